@@ -0,0 +1,266 @@
+// Copyright © 2026 Mikael Berthe <mikael@lilotux.net>
+//
+// Licensed under the MIT license.
+// Please see the LICENSE file is this directory.
+
+package cmd
+
+import (
+	"encoding/csv"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/McKael/madon/v3"
+)
+
+var accountDiffOpts struct {
+	relation string // following or followers
+	bConfig  string
+	set      string
+	csv      bool
+	apply    bool
+	delay    time.Duration
+}
+
+func init() {
+	accountsCmd.AddCommand(accountDiffSubcommand)
+
+	accountDiffSubcommand.Flags().StringVar(&accountDiffOpts.relation, "relation", "following", "Account sets to compare (following, followers)")
+	accountDiffSubcommand.Flags().StringVar(&accountDiffOpts.bConfig, "b-config", "", "Configuration file (profile) for the account B")
+	accountDiffSubcommand.Flags().StringVar(&accountDiffOpts.set, "set", "only-a", "Set to display (only-a, only-b, common)")
+	accountDiffSubcommand.Flags().BoolVar(&accountDiffOpts.csv, "csv", false, "Display the sets as CSV (all the sets unless --set is used)")
+	accountDiffSubcommand.Flags().BoolVar(&accountDiffOpts.apply, "apply", false, "Follow on B the accounts only followed by A")
+	accountDiffSubcommand.Flags().DurationVar(&accountDiffOpts.delay, "delay", time.Second, "Delay between two follow requests (with --apply)")
+}
+
+var accountDiffSubcommand = &cobra.Command{
+	Use:   "diff [ACCOUNT_A [ACCOUNT_B]]",
+	Short: "Compare the followed accounts (or followers) of two accounts",
+	Long: `Compare the followed accounts (or followers) of two accounts
+
+The accounts can be given as account IDs, user@domain addresses or URLs;
+the current user is used by default.
+The account B can be on another instance (or use other credentials): use
+--b-config to provide another configuration file.  The accounts are compared
+using their addresses.
+
+The accounts followed only by A (only-a), only by B (only-b), or by both
+(common) are displayed.  With --csv, all the sets are displayed (unless
+--set is used), with the set name in the first column.
+
+With --apply, the accounts followed only by A are followed on B (B must be
+the user of its profile).`,
+	Example: `  madonctl account diff Gargron@mastodon.social
+  madonctl account diff --set common --relation followers 1234
+  madonctl account diff --b-config ~/.config/madonctl/new.yaml --csv
+  madonctl account diff --b-config ~/.config/madonctl/new.yaml --apply`,
+	RunE: accountDiffRunE,
+}
+
+// lookupAccountWith returns the account identified by user (an ID, an
+// address or an URL) using the client c.  If user is empty, the current
+// user account is returned.
+func lookupAccountWith(c *madon.Client, user string) (*madon.Account, error) {
+	if user == "" {
+		return c.GetCurrentAccount()
+	}
+	r := newAccountResolver(c)
+	defer r.cache.save()
+	return r.resolve(user)
+}
+
+// accountDiffSide contains the data of a compared account
+type accountDiffSide struct {
+	client      *madon.Client
+	account     *madon.Account
+	localDomain string
+	accounts    []madon.Account
+	index       map[string]bool // Addresses (lowercase)
+}
+
+func (s *accountDiffSide) load(user, relation string) error {
+	var err error
+	if s.account, err = lookupAccountWith(s.client, user); err != nil {
+		return errors.Wrapf(err, "cannot find account '%s'", user)
+	}
+	if instance, err := s.client.GetCurrentInstance(); err == nil {
+		s.localDomain = instance.URI
+	}
+
+	all := &madon.LimitParams{All: true}
+	if relation == "followers" {
+		s.accounts, err = s.client.GetAccountFollowers(s.account.ID, all)
+	} else {
+		s.accounts, err = s.client.GetAccountFollowing(s.account.ID, all)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.index = make(map[string]bool, len(s.accounts))
+	for i := range s.accounts {
+		s.index[s.address(&s.accounts[i])] = true
+	}
+	return nil
+}
+
+// address returns the normalized address of an account
+func (s *accountDiffSide) address(a *madon.Account) string {
+	return strings.ToLower(exportAccountAddress(a, s.localDomain))
+}
+
+// diff returns the accounts of s that are not (or are, if common is true)
+// in the other set
+func (s *accountDiffSide) diff(other *accountDiffSide, common bool) []madon.Account {
+	list := []madon.Account{}
+	for i := range s.accounts {
+		if other.index[s.address(&s.accounts[i])] == common {
+			list = append(list, s.accounts[i])
+		}
+	}
+	return list
+}
+
+func accountDiffRunE(cmd *cobra.Command, args []string) error {
+	opt := accountDiffOpts
+
+	if len(args) > 2 {
+		return errors.New("too many arguments")
+	}
+	switch opt.relation {
+	case "following", "followers":
+	default:
+		return errors.Errorf("invalid relation '%s'", opt.relation)
+	}
+	switch opt.set {
+	case "only-a", "only-b", "common":
+	default:
+		return errors.Errorf("invalid set '%s'", opt.set)
+	}
+	if opt.apply && opt.relation != "following" {
+		return errors.New("--apply can only be used with the following relation")
+	}
+
+	var userA, userB string
+	if len(args) > 0 {
+		userA = args[0]
+	}
+	if len(args) > 1 {
+		userB = args[1]
+	}
+	if opt.bConfig == "" && userA == userB {
+		return errors.New("the accounts A and B are the same")
+	}
+
+	if err := madonInit(true); err != nil {
+		return err
+	}
+	a := &accountDiffSide{client: gClient}
+	b := &accountDiffSide{client: gClient}
+	if opt.bConfig != "" {
+		var err error
+		if b.client, err = madonProfileClient(opt.bConfig); err != nil {
+			return err
+		}
+	}
+
+	if err := a.load(userA, opt.relation); err != nil {
+		errPrint("Error: %s", err.Error())
+		os.Exit(1)
+	}
+	if err := b.load(userB, opt.relation); err != nil {
+		errPrint("Error: %s", err.Error())
+		os.Exit(1)
+	}
+
+	sets := map[string][]madon.Account{
+		"only-a": a.diff(b, false),
+		"only-b": b.diff(a, false),
+		"common": a.diff(b, true),
+	}
+	if verbose {
+		errPrint("A: %d account(s), B: %d account(s), only A: %d, only B: %d, common: %d",
+			len(a.accounts), len(b.accounts), len(sets["only-a"]), len(sets["only-b"]), len(sets["common"]))
+	}
+
+	if opt.apply {
+		return accountDiffApply(b, sets["only-a"], a.localDomain)
+	}
+
+	if opt.csv {
+		names := []string{"only-a", "only-b", "common"}
+		if cmd.Flags().Lookup("set").Changed {
+			names = []string{opt.set}
+		}
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{"Set", "Account address"})
+		for _, name := range names {
+			side := a
+			if name == "only-b" {
+				side = b
+			}
+			for i := range sets[name] {
+				w.Write([]string{name, exportAccountAddress(&sets[name][i], side.localDomain)})
+			}
+		}
+		w.Flush()
+		return w.Error()
+	}
+
+	p, err := getPrinter()
+	if err != nil {
+		errPrint("Error: %s", err.Error())
+		os.Exit(1)
+	}
+	return p.printObj(sets[opt.set])
+}
+
+// accountDiffApply follows the accounts on B
+func accountDiffApply(b *accountDiffSide, accounts []madon.Account, localDomain string) error {
+	me, err := b.client.GetCurrentAccount()
+	if err != nil {
+		errPrint("Error: %s", err.Error())
+		os.Exit(1)
+	}
+	if me.ID != b.account.ID {
+		return errors.New("--apply: the account B must be the current user of its profile")
+	}
+
+	resolver := newAccountResolver(b.client)
+	var followed, failed int
+	for i := range accounts {
+		if i > 0 && accountDiffOpts.delay > 0 {
+			time.Sleep(accountDiffOpts.delay)
+		}
+		address := exportAccountAddress(&accounts[i], localDomain)
+		err := withRateLimitRetry(func() error {
+			account, err := resolver.resolve(address)
+			if err != nil {
+				return err
+			}
+			_, err = b.client.FollowAccount(account.ID, nil)
+			return err
+		})
+		if err != nil {
+			errPrint("Error: cannot follow '%s': %s", address, err.Error())
+			failed++
+			continue
+		}
+		followed++
+		if verbose {
+			errPrint("Followed %s", address)
+		}
+	}
+
+	resolver.cache.save()
+
+	errPrint("%d account(s) followed, %d failure(s)", followed, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+// Copyright © 2026 Mikael Berthe <mikael@lilotux.net>
+//
+// Licensed under the MIT license.
+// Please see the LICENSE file is this directory.
+
+package cmd
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/McKael/madon/v3"
+	"github.com/McKael/madonctl/v3/mastodon"
+)
+
+func init() {
+	accountsCmd.AddCommand(accountExportSubcommand)
+}
+
+var accountExportSubcommand = &cobra.Command{
+	Use:   "export following|followers|blocks|mutes|domain-blocks|lists [FILE]",
+	Short: "Export account data to a CSV file",
+	Long: `Export account data to a CSV file
+
+The data of the current user are exported in the CSV formats used by the
+Mastodon import page:
+- following: account address, show boosts, notify on new posts, languages
+- followers: account address (not supported by the Mastodon importer)
+- blocks: account address
+- mutes: account address, hide notifications
+- domain-blocks: domain
+- lists: list title, account address
+
+The CSV data are written to the standard output if no file is given.`,
+	Example: `  madonctl account export following > following_accounts.csv
+  madonctl account export blocks blocked_accounts.csv
+  madonctl account export lists lists.csv`,
+	ValidArgs: []string{"following", "followers", "blocks", "mutes", "domain-blocks", "lists"},
+	RunE:      accountExportRunE,
+}
+
+// exportAccountAddress returns the full address of an account (the local
+// accounts are given the local domain, like the Mastodon exports)
+func exportAccountAddress(a *madon.Account, localDomain string) string {
+	if strings.Contains(a.Acct, "@") || localDomain == "" {
+		address, _ := accountAddress(a)
+		return address
+	}
+	return a.Acct + "@" + localDomain
+}
+
+// accountRelationships returns the relationships with the accounts,
+// indexed by account ID
+func accountRelationships(accounts []madon.Account) (map[madon.ActivityID]mastodon.Relationship, error) {
+	ids := make([]madon.ActivityID, len(accounts))
+	for i, a := range accounts {
+		ids[i] = a.ID
+	}
+	rl, err := gExtClient.GetRelationships(ids)
+	if err != nil {
+		return nil, err
+	}
+	relationships := make(map[madon.ActivityID]mastodon.Relationship, len(rl))
+	for _, r := range rl {
+		relationships[r.ID] = r
+	}
+	return relationships, nil
+}
+
+// accountExportRecords fetches the data to export
+func accountExportRecords(what string) ([][]string, error) {
+	all := &madon.LimitParams{All: true}
+
+	// The local domain is used for the local account addresses
+	var localDomain string
+	if instance, err := gClient.GetCurrentInstance(); err == nil {
+		localDomain = instance.URI
+	}
+
+	var records [][]string
+	switch what {
+	case "following", "followers":
+		me, err := gClient.GetCurrentAccount()
+		if err != nil {
+			return nil, err
+		}
+		if what == "followers" {
+			accounts, err := gClient.GetAccountFollowers(me.ID, all)
+			if err != nil {
+				return nil, err
+			}
+			for i := range accounts {
+				records = append(records, []string{exportAccountAddress(&accounts[i], localDomain)})
+			}
+			break
+		}
+
+		accounts, err := gClient.GetAccountFollowing(me.ID, all)
+		if err != nil {
+			return nil, err
+		}
+		relationships, err := accountRelationships(accounts)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, followsCSVHeader)
+		for i, a := range accounts {
+			r, ok := relationships[a.ID]
+			if !ok {
+				r.ShowingReblogs = true // Default value
+			}
+			records = append(records, []string{
+				exportAccountAddress(&accounts[i], localDomain),
+				strconv.FormatBool(r.ShowingReblogs),
+				strconv.FormatBool(r.Notifying),
+				strings.Join(r.Languages, ", "),
+			})
+		}
+	case "blocks":
+		accounts, err := gClient.GetBlockedAccounts(all)
+		if err != nil {
+			return nil, err
+		}
+		for i := range accounts {
+			records = append(records, []string{exportAccountAddress(&accounts[i], localDomain)})
+		}
+	case "mutes":
+		accounts, err := gClient.GetMutedAccounts(all)
+		if err != nil {
+			return nil, err
+		}
+		relationships, err := accountRelationships(accounts)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, []string{"Account address", "Hide notifications"})
+		for i, a := range accounts {
+			r, ok := relationships[a.ID]
+			hide := !ok || r.MutingNotifications
+			records = append(records, []string{
+				exportAccountAddress(&accounts[i], localDomain),
+				strconv.FormatBool(hide),
+			})
+		}
+	case "domain-blocks":
+		domains, err := gClient.GetBlockedDomains(all)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range domains {
+			records = append(records, []string{string(d)})
+		}
+	case "lists":
+		lists, err := gClient.GetLists("", all)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range lists {
+			accounts, err := gClient.GetListAccounts(l.ID, all)
+			if err != nil {
+				return nil, err
+			}
+			for i := range accounts {
+				records = append(records, []string{l.Title, exportAccountAddress(&accounts[i], localDomain)})
+			}
+		}
+	default:
+		return nil, errors.Errorf("unsupported export type '%s'", what)
+	}
+	return records, nil
+}
+
+func accountExportRunE(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return errors.New("wrong usage: please provide an export type (and an optional file)")
+	}
+	switch args[0] {
+	case "following", "followers", "blocks", "mutes", "domain-blocks", "lists":
+	default:
+		return errors.Errorf("unsupported export type '%s'", args[0])
+	}
+
+	if err := madonInit(true); err != nil {
+		return err
+	}
+
+	records, err := accountExportRecords(args[0])
+	if err != nil {
+		errPrint("Error: %s", err.Error())
+		os.Exit(1)
+	}
+
+	var w io.Writer = os.Stdout
+	if len(args) == 2 {
+		f, err := os.Create(args[1])
+		if err != nil {
+			return errors.Wrap(err, "cannot create export file")
+		}
+		defer f.Close()
+		w = f
+	}
+
+	cw := csv.NewWriter(w)
+	cw.WriteAll(records)
+	if err := cw.Error(); err != nil {
+		return errors.Wrap(err, "cannot write CSV data")
+	}
+	if verbose {
+		errPrint("%d record(s) exported", len(records))
+	}
+	return nil
+}
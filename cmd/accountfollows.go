@@ -0,0 +1,114 @@
+// Copyright © 2026 Mikael Berthe <mikael@lilotux.net>
+//
+// Licensed under the MIT license.
+// Please see the LICENSE file is this directory.
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/McKael/madon/v3"
+	"github.com/McKael/madonctl/v3/mastodon"
+)
+
+// accountFollowsFilter fetches the complete followers (or following) list
+// of the account and applies the filters of the followers/following
+// subcommands
+func accountFollowsFilter(subcmd string, accountID madon.ActivityID) ([]madon.Account, error) {
+	opt := accountsOpts
+	all := &madon.LimitParams{All: true}
+
+	var accounts, others []mastodon.Account
+	var err error
+	if subcmd == "followers" {
+		accounts, err = gExtClient.GetAccountFollowers(accountID, all)
+	} else {
+		accounts, err = gExtClient.GetAccountFollowing(accountID, all)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The other list is only needed for the set operations
+	if opt.notFollowedBack || opt.notFollowingBack || opt.mutual {
+		if subcmd == "followers" {
+			others, err = gExtClient.GetAccountFollowing(accountID, all)
+		} else {
+			others, err = gExtClient.GetAccountFollowers(accountID, all)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	index := make(map[madon.ActivityID]bool, len(others))
+	for _, a := range others {
+		index[a.ID] = true
+	}
+
+	var threshold time.Time
+	if opt.inactive != "" {
+		d, err := parseDuration(opt.inactive)
+		if err != nil {
+			return nil, err
+		}
+		threshold = time.Now().Add(-d)
+	}
+
+	list := []madon.Account{}
+	for i := range accounts {
+		a := &accounts[i]
+		if opt.mutual && !index[a.ID] {
+			continue
+		}
+		if (opt.notFollowedBack || opt.notFollowingBack) && index[a.ID] {
+			continue
+		}
+		if !threshold.IsZero() {
+			// Accounts that have never posted are considered inactive
+			if t, ok := a.LastStatusTime(); ok && t.After(threshold) {
+				continue
+			}
+		}
+		list = append(list, a.Account)
+	}
+
+	if verbose {
+		errPrint("%d account(s) selected out of %d", len(list), len(accounts))
+	}
+	return list, nil
+}
+
+// accountBulkUnfollow unfollows the accounts and returns the list of the
+// accounts that have been unfollowed
+// An error is returned if some accounts could not be unfollowed.
+func accountBulkUnfollow(accounts []madon.Account) ([]madon.Account, error) {
+	unfollowed := []madon.Account{}
+	var failed int
+	for i, a := range accounts {
+		if i > 0 && accountsOpts.delay > 0 {
+			time.Sleep(accountsOpts.delay)
+		}
+
+		err := withRateLimitRetry(func() error {
+			_, err := gClient.UnfollowAccount(a.ID)
+			return err
+		})
+		if err != nil {
+			errPrint("Error: cannot unfollow '%s': %s", a.Acct, err.Error())
+			failed++
+			continue
+		}
+		unfollowed = append(unfollowed, a)
+		if verbose {
+			errPrint("Unfollowed %s", a.Acct)
+		}
+	}
+	errPrint("%d account(s) unfollowed, %d failure(s)", len(unfollowed), failed)
+	if failed > 0 {
+		return unfollowed, errors.Errorf("%d account(s) could not be unfollowed", failed)
+	}
+	return unfollowed, nil
+}
@@ -0,0 +1,300 @@
+// Copyright © 2026 Mikael Berthe <mikael@lilotux.net>
+//
+// Licensed under the MIT license.
+// Please see the LICENSE file is this directory.
+
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/McKael/madon/v3"
+	"github.com/McKael/madonctl/v3/mastodon"
+)
+
+// Rate-limited requests are retried when the limit is reset (or after the
+// default delay if the reset time is unknown), unless the reset is too far.
+const (
+	accountImportRateLimitDelay   = 5 * time.Minute
+	accountImportRateLimitMaxWait = 15 * time.Minute
+	accountImportRateLimitRetries = 3
+)
+
+var accountImportOpts struct {
+	dryRun   bool
+	delay    time.Duration
+	failures string
+}
+
+// Header of the Mastodon follows export file
+var followsCSVHeader = []string{"Account address", "Show boosts", "Notify on new posts", "Languages"}
+
+func init() {
+	accountsCmd.AddCommand(accountImportSubcommand)
+
+	accountImportSubcommand.Flags().BoolVar(&accountImportOpts.dryRun, "dry-run", false, "Resolve the accounts but do not follow them")
+	accountImportSubcommand.Flags().DurationVar(&accountImportOpts.delay, "delay", time.Second, "Delay between two accounts (also with --dry-run)")
+	accountImportSubcommand.Flags().StringVar(&accountImportOpts.failures, "failures", "", "Failure report file (default: FILE-failed.csv)")
+}
+
+var accountImportSubcommand = &cobra.Command{
+	Use:   "import follows FILE",
+	Short: "Import data from a Mastodon export file",
+	Long: `Import data from a Mastodon export file
+
+The follows type imports a list of followed accounts, in the CSV format
+exported by Mastodon (following_accounts.csv): account address, show boosts,
+notify on new posts and languages.  The remote accounts are resolved using
+the lookup API, WebFinger or the search API.
+
+The requests are paced (see --delay); when the server rate limit is reached,
+madonctl waits until the limit is reset before retrying.
+The accounts that could not be followed are written to a failure report
+(in the same format, with an additional error column); the import can be
+resumed by importing this report.`,
+	Example: `  madonctl account import follows following_accounts.csv --dry-run
+  madonctl account import follows following_accounts.csv
+  madonctl account import follows following_accounts-failed.csv`,
+	ValidArgs: []string{"follows"},
+	RunE:      accountImportRunE,
+}
+
+// followEntry is an entry from a follows export file
+type followEntry struct {
+	address   string
+	reblogs   bool
+	notify    bool
+	languages []string
+	record    []string // Original CSV record
+}
+
+// readFollowsCSV reads a follows export file
+// The header line is optional; the files with a single column (account
+// addresses) are accepted.
+func readFollowsCSV(r io.Reader) ([]followEntry, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	var entries []followEntry
+	for line := 1; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot parse CSV file")
+		}
+		if len(rec) == 0 || strings.TrimSpace(rec[0]) == "" {
+			continue
+		}
+		if line == 1 && strings.EqualFold(rec[0], followsCSVHeader[0]) {
+			continue // Header
+		}
+
+		fe := followEntry{
+			address: strings.TrimPrefix(strings.TrimSpace(rec[0]), "@"),
+			reblogs: true,
+			record:  rec,
+		}
+		parseBool := func(i int, def bool) (bool, error) {
+			if len(rec) <= i || strings.TrimSpace(rec[i]) == "" {
+				return def, nil
+			}
+			b, err := strconv.ParseBool(strings.TrimSpace(rec[i]))
+			if err != nil {
+				return def, errors.Errorf("invalid value '%s' (line %d)", rec[i], line)
+			}
+			return b, nil
+		}
+		if fe.reblogs, err = parseBool(1, true); err != nil {
+			return nil, err
+		}
+		if fe.notify, err = parseBool(2, false); err != nil {
+			return nil, err
+		}
+		if len(rec) > 3 {
+			for _, l := range strings.Split(rec[3], ",") {
+				if l = strings.TrimSpace(l); l != "" {
+					fe.languages = append(fe.languages, l)
+				}
+			}
+		}
+		entries = append(entries, fe)
+	}
+	return entries, nil
+}
+
+// isRateLimited returns true if the error is a rate limit error
+func isRateLimited(err error) bool {
+	var apiErr *mastodon.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests
+	}
+	// The madon library errors only have a text description
+	return err != nil && strings.Contains(err.Error(), "bad server status code (429)")
+}
+
+// rateLimitWait returns the delay before the rate limit is reset
+func rateLimitWait(err error) time.Duration {
+	var apiErr *mastodon.APIError
+	if !errors.As(err, &apiErr) || apiErr.RateLimitReset.IsZero() {
+		return accountImportRateLimitDelay
+	}
+	wait := time.Until(apiErr.RateLimitReset) + time.Second
+	if wait < time.Second {
+		wait = time.Second
+	}
+	return wait
+}
+
+// withRateLimitRetry calls f again (when the limit is reset) when the server
+// rate limit is reached
+func withRateLimitRetry(f func() error) error {
+	err := f()
+	for i := 1; isRateLimited(err) && i < accountImportRateLimitRetries; i++ {
+		wait := rateLimitWait(err)
+		if wait > accountImportRateLimitMaxWait {
+			errPrint("Rate limit reached, reset in %v", wait.Round(time.Second))
+			break
+		}
+		errPrint("Rate limit reached, waiting %v...", wait.Round(time.Second))
+		time.Sleep(wait)
+		err = f()
+	}
+	return err
+}
+
+func accountImportRunE(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return errors.New("wrong usage: please provide an import type and a file")
+	}
+	if args[0] != "follows" {
+		return errors.Errorf("unsupported import type '%s'", args[0])
+	}
+
+	f, err := os.Open(args[1])
+	if err != nil {
+		return errors.Wrap(err, "cannot open import file")
+	}
+	entries, err := readFollowsCSV(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return errors.New("no account found in the import file")
+	}
+
+	failureFile := accountImportOpts.failures
+	if failureFile == "" {
+		failureFile = strings.TrimSuffix(args[1], ".csv") + "-failed.csv"
+	}
+
+	if err := madonInit(true); err != nil {
+		return err
+	}
+
+	resolver := newAccountResolver(gClient)
+	var failures [][]string
+	var followed int
+	for i, fe := range entries {
+		if i > 0 && accountImportOpts.delay > 0 {
+			time.Sleep(accountImportOpts.delay)
+		}
+
+		var account *madon.Account
+		err := withRateLimitRetry(func() (err error) {
+			account, err = resolver.resolve(fe.address)
+			return
+		})
+		if err == nil && !accountImportOpts.dryRun {
+			fp := mastodon.FollowParams{
+				Reblogs:   &fe.reblogs,
+				Notify:    &fe.notify,
+				Languages: fe.languages,
+			}
+			err = withRateLimitRetry(func() error {
+				_, err := gExtClient.FollowAccount(account.ID, fp)
+				return err
+			})
+		}
+
+		if err != nil {
+			errPrint("Error: cannot follow '%s': %s", fe.address, err.Error())
+			failures = append(failures, followRecordWithError(fe, err))
+			if isRateLimited(err) {
+				// Give up, the remaining accounts are added to the report
+				errPrint("Rate limit reached, aborting")
+				for _, rest := range entries[i+1:] {
+					failures = append(failures, followRecordWithError(rest, errors.New("not processed")))
+				}
+				break
+			}
+			continue
+		}
+
+		if accountImportOpts.dryRun {
+			fmt.Printf("Would follow %s (account ID %s)\n", fe.address, account.ID)
+			continue
+		}
+		followed++
+		if verbose {
+			errPrint("Followed %s (%d/%d)", fe.address, i+1, len(entries))
+		}
+	}
+
+	resolver.cache.save()
+
+	if accountImportOpts.dryRun {
+		errPrint("Dry run: %d account(s) resolved, %d failure(s)", len(entries)-len(failures), len(failures))
+		return nil
+	}
+
+	errPrint("%d account(s) followed, %d failure(s)", followed, len(failures))
+	if len(failures) > 0 {
+		if err := writeFollowsCSV(failureFile, failures); err != nil {
+			errPrint("Error: %s", err.Error())
+			os.Exit(1)
+		}
+		errPrint("The failures have been written to '%s'", failureFile)
+		os.Exit(1)
+	}
+	return nil
+}
+
+// followRecordWithError returns the CSV record of a follow entry, with an
+// error column
+func followRecordWithError(fe followEntry, err error) []string {
+	rec := make([]string, len(followsCSVHeader)+1)
+	copy(rec, fe.record)
+	rec[len(followsCSVHeader)] = err.Error()
+	return rec
+}
+
+// writeFollowsCSV writes a follows CSV file, with an additional error column
+func writeFollowsCSV(filename string, records [][]string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return errors.Wrap(err, "cannot create report file")
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	w.Write(append(append([]string(nil), followsCSVHeader...), "Error"))
+	w.WriteAll(records)
+	if err := w.Error(); err != nil {
+		return errors.Wrap(err, "cannot write report file")
+	}
+	return nil
+}
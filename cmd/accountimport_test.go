@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/McKael/madonctl/v3/mastodon"
+)
+
+func TestReadFollowsCSV(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []followEntry
+		err      bool
+	}{
+		{
+			name: "mastodon export",
+			input: "Account address,Show boosts,Notify on new posts,Languages\n" +
+				"alice@example.com,true,false,\n" +
+				"bob@example.org,false,true,\"en,fr\"\n",
+			expected: []followEntry{
+				{address: "alice@example.com", reblogs: true, notify: false},
+				{address: "bob@example.org", reblogs: false, notify: true, languages: []string{"en", "fr"}},
+			},
+		},
+		{
+			name:  "addresses only",
+			input: "@alice@example.com\n\ncarol\n",
+			expected: []followEntry{
+				{address: "alice@example.com", reblogs: true},
+				{address: "carol", reblogs: true},
+			},
+		},
+		{
+			name:  "header is case-insensitive",
+			input: "account address,show boosts\nalice@example.com, false\n",
+			expected: []followEntry{
+				{address: "alice@example.com", reblogs: false},
+			},
+		},
+		{
+			name:  "header only on the first line",
+			input: "alice@example.com\nAccount address\n",
+			expected: []followEntry{
+				{address: "alice@example.com", reblogs: true},
+				{address: "Account address", reblogs: true},
+			},
+		},
+		{
+			name:  "invalid boolean",
+			input: "alice@example.com,maybe\n",
+			err:   true,
+		},
+		{
+			name:  "invalid CSV",
+			input: "\"alice@example.com\n",
+			err:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		entries, err := readFollowsCSV(strings.NewReader(tt.input))
+		if tt.err {
+			assert.NotNil(t, err, tt.name)
+			continue
+		}
+		assert.Nil(t, err, tt.name)
+		// The original records are checked by TestFollowsCSVRoundTrip
+		for i := range entries {
+			entries[i].record = nil
+		}
+		assert.Equal(t, tt.expected, entries, tt.name)
+	}
+}
+
+func TestFollowsCSVRoundTrip(t *testing.T) {
+	input := "Account address,Show boosts,Notify on new posts,Languages\n" +
+		"alice@example.com,true,false,\n" +
+		"bob@example.org,false,true,\"en,fr\"\n" +
+		"carol\n"
+	entries, err := readFollowsCSV(strings.NewReader(input))
+	assert.Nil(t, err)
+
+	var records [][]string
+	for _, fe := range entries {
+		records = append(records, followRecordWithError(fe, errors.New("failed: "+fe.address)))
+	}
+	report := filepath.Join(t.TempDir(), "failed.csv")
+	assert.Nil(t, writeFollowsCSV(report, records))
+
+	// The failure report can be imported again
+	f, err := os.Open(report)
+	assert.Nil(t, err)
+	defer f.Close()
+	reimported, err := readFollowsCSV(f)
+	assert.Nil(t, err)
+	assert.Equal(t, len(entries), len(reimported))
+	for i, fe := range reimported {
+		assert.Equal(t, entries[i].address, fe.address)
+		assert.Equal(t, entries[i].reblogs, fe.reblogs)
+		assert.Equal(t, entries[i].notify, fe.notify)
+		assert.Equal(t, entries[i].languages, fe.languages)
+		assert.Equal(t, "failed: "+fe.address, fe.record[len(followsCSVHeader)])
+	}
+}
+
+func TestIsRateLimited(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{nil, false},
+		{errors.New("bad server status code (500): boom"), false},
+		{errors.New("bad server status code (429): Too many requests"), true},
+		{&mastodon.APIError{StatusCode: 429}, true},
+		{&mastodon.APIError{StatusCode: 404}, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, isRateLimited(tt.err), "%v", tt.err)
+	}
+}
+
+func TestRateLimitWait(t *testing.T) {
+	assert.Equal(t, accountImportRateLimitDelay, rateLimitWait(errors.New("bad server status code (429)")))
+	assert.Equal(t, accountImportRateLimitDelay, rateLimitWait(&mastodon.APIError{StatusCode: 429}))
+	assert.Equal(t, time.Second, rateLimitWait(&mastodon.APIError{
+		StatusCode:     429,
+		RateLimitReset: time.Now().Add(-time.Minute),
+	}))
+	wait := rateLimitWait(&mastodon.APIError{
+		StatusCode:     429,
+		RateLimitReset: time.Now().Add(time.Minute),
+	})
+	assert.True(t, wait > 50*time.Second && wait <= 61*time.Second, "wait: %v", wait)
+}
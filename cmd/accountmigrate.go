@@ -0,0 +1,213 @@
+// Copyright © 2026 Mikael Berthe <mikael@lilotux.net>
+//
+// Licensed under the MIT license.
+// Please see the LICENSE file is this directory.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/McKael/madon/v3"
+	"github.com/McKael/madonctl/v3/mastodon"
+)
+
+var accountMigrateOpts struct {
+	dryRun      bool
+	unfollowOld bool
+	delay       time.Duration
+}
+
+func init() {
+	accountsCmd.AddCommand(accountMigrateFollowsSubcommand)
+
+	accountMigrateFollowsSubcommand.Flags().BoolVar(&accountMigrateOpts.dryRun, "dry-run", false, "Only display the moved accounts")
+	accountMigrateFollowsSubcommand.Flags().BoolVar(&accountMigrateOpts.unfollowOld, "unfollow-old", false, "Unfollow the old accounts")
+	accountMigrateFollowsSubcommand.Flags().DurationVar(&accountMigrateOpts.delay, "delay", time.Second, "Delay between two accounts")
+}
+
+var accountMigrateFollowsSubcommand = &cobra.Command{
+	Use:   "migrate-follows",
+	Short: "Follow the new accounts of the followed accounts that have moved",
+	Long: `Follow the new accounts of the followed accounts that have moved
+
+The accounts followed by the current user are scanned; when an account has
+moved, its new account is followed with the same settings (show boosts,
+notifications and languages) and added to the same lists.
+With --unfollow-old, the old accounts are unfollowed (unless the new account
+could not be added to all the lists).
+
+A line is displayed for each moved account, with the changes.`,
+	Example: `  madonctl account migrate-follows --dry-run
+  madonctl account migrate-follows
+  madonctl account migrate-follows --unfollow-old`,
+	RunE: accountMigrateFollowsRunE,
+}
+
+// movedTarget returns the final account of a moved account
+func movedTarget(a *madon.Account) *madon.Account {
+	target := a.Moved
+	for i := 0; target.Moved != nil && i < 10; i++ {
+		target = target.Moved
+	}
+	return target
+}
+
+func accountMigrateFollowsRunE(cmd *cobra.Command, args []string) error {
+	opt := accountMigrateOpts
+
+	if len(args) > 0 {
+		return errors.New("too many arguments")
+	}
+
+	if err := madonInit(true); err != nil {
+		return err
+	}
+
+	me, err := gClient.GetCurrentAccount()
+	if err != nil {
+		errPrint("Error: %s", err.Error())
+		os.Exit(1)
+	}
+	following, err := gClient.GetAccountFollowing(me.ID, &madon.LimitParams{All: true})
+	if err != nil {
+		errPrint("Error: %s", err.Error())
+		os.Exit(1)
+	}
+
+	var moved []madon.Account
+	var targets []madon.Account
+	for i := range following {
+		if following[i].Moved != nil {
+			moved = append(moved, following[i])
+			targets = append(targets, *movedTarget(&following[i]))
+		}
+	}
+	if verbose {
+		errPrint("%d followed account(s), %d moved", len(following), len(moved))
+	}
+	if len(moved) == 0 {
+		return nil
+	}
+
+	relationships, err := accountRelationships(append(moved, targets...))
+	if err != nil {
+		errPrint("Error: %s", err.Error())
+		os.Exit(1)
+	}
+
+	var migrated, failed int
+	for i := range moved {
+		old, target := &moved[i], &targets[i]
+		if i > 0 && opt.delay > 0 && !opt.dryRun {
+			time.Sleep(opt.delay)
+		}
+
+		lists, err := gClient.GetLists(old.ID, nil)
+		if err != nil {
+			errPrint("Error: cannot get the lists of '%s': %s", old.Acct, err.Error())
+		}
+		var listTitles []string
+		for _, l := range lists {
+			listTitles = append(listTitles, l.Title)
+		}
+
+		report := fmt.Sprintf("%s -> %s", old.Acct, target.Acct)
+		if opt.dryRun {
+			if len(listTitles) > 0 {
+				report += fmt.Sprintf(" (lists: %s)", strings.Join(listTitles, ", "))
+			}
+			fmt.Println(report)
+			continue
+		}
+
+		changes, err := accountMigrateFollow(old, target, relationships, lists)
+		if len(changes) > 0 {
+			report += ": " + strings.Join(changes, ", ")
+		}
+		fmt.Println(report)
+		if err != nil {
+			errPrint("Error: cannot migrate '%s': %s", old.Acct, err.Error())
+			failed++
+			continue
+		}
+		migrated++
+	}
+
+	if opt.dryRun {
+		errPrint("Dry run: %d moved account(s)", len(moved))
+		return nil
+	}
+	errPrint("%d account(s) migrated, %d failure(s)", migrated, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// accountMigrateFollow follows the new account of a moved account and
+// returns the list of the changes
+func accountMigrateFollow(old, target *madon.Account, relationships map[madon.ActivityID]mastodon.Relationship, lists []madon.List) ([]string, error) {
+	var changes []string
+
+	if r := relationships[target.ID]; r.Following || r.Requested {
+		changes = append(changes, "already followed")
+	} else {
+		fp := mastodon.FollowParams{}
+		if r, ok := relationships[old.ID]; ok {
+			fp.Reblogs = &r.ShowingReblogs
+			fp.Notify = &r.Notifying
+			fp.Languages = r.Languages
+		}
+		var rel *mastodon.Relationship
+		err := withRateLimitRetry(func() (err error) {
+			rel, err = gExtClient.FollowAccount(target.ID, fp)
+			return
+		})
+		if err != nil {
+			return changes, errors.Wrap(err, "cannot follow the new account")
+		}
+		if rel.Requested && !rel.Following {
+			changes = append(changes, "follow requested")
+		} else {
+			changes = append(changes, "followed")
+		}
+	}
+
+	var listFailures int
+	for _, l := range lists {
+		err := withRateLimitRetry(func() error {
+			return gClient.AddListAccounts(l.ID, []madon.ActivityID{target.ID})
+		})
+		if err != nil {
+			// This fails when the follow request is pending
+			errPrint("Error: cannot add '%s' to the list '%s': %s", target.Acct, l.Title, err.Error())
+			listFailures++
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("added to list '%s'", l.Title))
+	}
+	if listFailures > 0 {
+		// The old account is kept, so that its list memberships are not
+		// lost.
+		return changes, errors.Errorf("cannot add the new account to %d list(s)", listFailures)
+	}
+
+	if accountMigrateOpts.unfollowOld {
+		err := withRateLimitRetry(func() error {
+			_, err := gClient.UnfollowAccount(old.ID)
+			return err
+		})
+		if err != nil {
+			return changes, errors.Wrap(err, "cannot unfollow the old account")
+		}
+		changes = append(changes, "old account unfollowed")
+	}
+	return changes, nil
+}
@@ -7,17 +7,18 @@ package cmd
 
 import (
 	"os"
-	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	flag "github.com/spf13/pflag"
 
 	"github.com/McKael/madon/v3"
+	"github.com/McKael/madonctl/v3/mastodon"
 )
 
-var accountUpdateFlags, accountMuteFlags, accountFollowFlags *flag.FlagSet
+var accountUpdateFlags, accountMuteFlags, accountFollowFlags, accountNoteFlags *flag.FlagSet
 
 var accountsOpts struct {
 	accountID             madon.ActivityID
@@ -28,7 +29,8 @@ var accountsOpts struct {
 	onlyMedia, onlyPinned bool             // For acccount statuses
 	excludeReplies        bool             // For acccount statuses
 	remoteUID             string           // For account follow
-	reblogs               bool             // For account follow
+	reblogs, notify       bool             // For account follow
+	languages             string           // For account follow
 	acceptFR, rejectFR    bool             // For account follow_requests
 	list                  bool             // For account follow_requests/reports
 	accountIDs            string           // For account relationships
@@ -42,7 +44,16 @@ var accountsOpts struct {
 	defaultSensitive      bool             // For account update
 	locked, bot           bool             // For account update
 	muteNotifications     bool             // For account mute
+	muteDuration          string           // For account mute
 	following             bool             // For account search
+	notFollowedBack       bool             // For account followers
+	notFollowingBack      bool             // For account following
+	mutual                bool             // For account followers/following
+	inactive              string           // For account following
+	unfollow              bool             // For account following
+	delay                 time.Duration    // For account following
+	privateNote           string           // For account note
+	clearNote             bool             // For account note
 }
 
 func init() {
@@ -52,7 +63,7 @@ func init() {
 	accountsCmd.AddCommand(accountSubcommands...)
 
 	// Global flags
-	accountsCmd.PersistentFlags().StringVarP(&accountsOpts.accountID, "account-id", "a", "", "Account ID number")
+	accountsCmd.PersistentFlags().StringVarP(&accountsOpts.accountID, "account-id", "a", "", "Account ID, address or URL")
 	accountsCmd.PersistentFlags().StringVarP(&accountsOpts.accountUID, "user-id", "u", "", "Account user ID")
 	accountsCmd.PersistentFlags().UintVarP(&accountsOpts.limit, "limit", "l", 0, "Limit number of API results")
 	accountsCmd.PersistentFlags().UintVarP(&accountsOpts.keep, "keep", "k", 0, "Limit number of results")
@@ -70,17 +81,31 @@ func init() {
 	accountFollowRequestsSubcommand.Flags().BoolVar(&accountsOpts.rejectFR, "reject", false, "Reject the follow request from the account ID")
 
 	accountMuteSubcommand.Flags().BoolVarP(&accountsOpts.muteNotifications, "notifications", "", true, "Mute the notifications")
+	accountMuteSubcommand.Flags().StringVar(&accountsOpts.muteDuration, "duration", "", "Mute duration (e.g. 24h, 7d; default: indefinite)")
 	accountFollowSubcommand.Flags().BoolVarP(&accountsOpts.reblogs, "show-reblogs", "", true, "Follow account's boosts")
+	accountFollowSubcommand.Flags().BoolVar(&accountsOpts.notify, "notify", false, "Notify when the account posts")
+	accountFollowSubcommand.Flags().StringVar(&accountsOpts.languages, "languages", "", "Comma-separated list of languages to display (ISO 639-1)")
 	accountFollowSubcommand.Flags().StringVarP(&accountsOpts.remoteUID, "remote", "r", "", "Follow remote account (user@domain)")
 
-	accountRelationshipsSubcommand.Flags().StringVar(&accountsOpts.accountIDs, "account-ids", "", "Comma-separated list of account IDs")
+	accountRelationshipsSubcommand.Flags().StringVar(&accountsOpts.accountIDs, "account-ids", "", "Comma-separated list of account IDs, addresses or URLs")
 
-	accountReportsSubcommand.Flags().StringVar(&accountsOpts.statusIDs, "status-ids", "", "Comma-separated list of status IDs")
+	accountReportsSubcommand.Flags().StringVar(&accountsOpts.statusIDs, "status-ids", "", "Comma-separated list of status IDs or URLs")
 	accountReportsSubcommand.Flags().StringVar(&accountsOpts.comment, "comment", "", "Report comment")
 	accountReportsSubcommand.Flags().BoolVar(&accountsOpts.list, "list", false, "List current user reports")
 
 	accountSearchSubcommand.Flags().BoolVar(&accountsOpts.following, "following", false, "Restrict search to accounts you are following")
 
+	accountNoteSubcommand.Flags().StringVar(&accountsOpts.privateNote, "set", "", "Set the private note")
+	accountNoteSubcommand.Flags().BoolVar(&accountsOpts.clearNote, "clear", false, "Remove the private note")
+
+	accountFollowersSubcommand.Flags().BoolVar(&accountsOpts.notFollowedBack, "not-followed-back", false, "Only followers you do not follow")
+	accountFollowersSubcommand.Flags().BoolVar(&accountsOpts.mutual, "mutual", false, "Only followers you follow")
+	accountFollowingSubcommand.Flags().BoolVar(&accountsOpts.notFollowingBack, "not-following-back", false, "Only followed accounts that do not follow you")
+	accountFollowingSubcommand.Flags().BoolVar(&accountsOpts.mutual, "mutual", false, "Only followed accounts that follow you")
+	accountFollowingSubcommand.Flags().StringVar(&accountsOpts.inactive, "inactive", "", "Only accounts without status for this duration (e.g. 180d)")
+	accountFollowingSubcommand.Flags().BoolVar(&accountsOpts.unfollow, "unfollow", false, "Unfollow the selected accounts")
+	accountFollowingSubcommand.Flags().DurationVar(&accountsOpts.delay, "delay", time.Second, "Delay between two unfollow requests (with --unfollow)")
+
 	accountUpdateSubcommand.Flags().StringVar(&accountsOpts.displayName, "display-name", "", "User display name")
 	accountUpdateSubcommand.Flags().StringVar(&accountsOpts.note, "note", "", "User note (a.k.a. bio)")
 	accountUpdateSubcommand.Flags().StringVar(&accountsOpts.avatar, "avatar", "", "User avatar image")
@@ -97,6 +122,7 @@ func init() {
 	accountUpdateFlags = accountUpdateSubcommand.Flags()
 	accountMuteFlags = accountMuteSubcommand.Flags()
 	accountFollowFlags = accountFollowSubcommand.Flags()
+	accountNoteFlags = accountNoteSubcommand.Flags()
 }
 
 // accountsCmd represents the accounts command
@@ -130,20 +156,6 @@ If no account ID is specified, the current user account is used.`,
 			return accountSubcommandsRunE(cmd.Name(), args)
 		},
 	},
-	&cobra.Command{
-		Use:   "followers",
-		Short: "Display the accounts following the specified account",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			return accountSubcommandsRunE(cmd.Name(), args)
-		},
-	},
-	&cobra.Command{
-		Use:   "following",
-		Short: "Display the accounts followed by the specified account",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			return accountSubcommandsRunE(cmd.Name(), args)
-		},
-	},
 	&cobra.Command{
 		Use:     "favourites",
 		Aliases: []string{"favorites", "favourited", "favorited"},
@@ -168,6 +180,8 @@ If no account ID is specified, the current user account is used.`,
 			return accountSubcommandsRunE(cmd.Name(), args)
 		},
 	},
+	accountFollowersSubcommand,
+	accountFollowingSubcommand,
 	accountSearchSubcommand,
 	accountStatusesSubcommand,
 	accountFollowRequestsSubcommand,
@@ -179,6 +193,7 @@ If no account ID is specified, the current user account is used.`,
 	accountUnmuteSubcommand,
 	accountPinSubcommand,
 	accountUnpinSubcommand,
+	accountNoteSubcommand,
 	accountRelationshipsSubcommand,
 	accountReportsSubcommand,
 	accountUpdateSubcommand,
@@ -197,6 +212,42 @@ This command will lookup an account remotely if the search term is in the
 	},
 }
 
+var accountFollowersSubcommand = &cobra.Command{
+	Use:   "followers",
+	Short: "Display the accounts following the specified account",
+	Long: `Display the accounts following the specified account
+
+With --not-followed-back or --mutual, the complete lists of followers and
+followed accounts are fetched and compared.`,
+	Example: `  madonctl account followers --limit 30
+  madonctl account followers --not-followed-back`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return accountSubcommandsRunE(cmd.Name(), args)
+	},
+}
+
+var accountFollowingSubcommand = &cobra.Command{
+	Use:   "following",
+	Short: "Display the accounts followed by the specified account",
+	Long: `Display the accounts followed by the specified account
+
+With --not-following-back or --mutual, the complete lists of followers and
+followed accounts are fetched and compared.
+With --inactive, only the accounts whose last status is older than the
+given duration (or that have never posted) are displayed.
+
+The selected accounts can be unfollowed with --unfollow (the user must be
+the current user, and at least one filter must be used).  The requests are
+paced (see --delay); the command fails if an account cannot be unfollowed.`,
+	Example: `  madonctl account following --all
+  madonctl account following --not-following-back
+  madonctl account following --inactive 180d
+  madonctl account following --inactive 365d --not-following-back --unfollow`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return accountSubcommandsRunE(cmd.Name(), args)
+	},
+}
+
 var accountStatusesSubcommand = &cobra.Command{
 	Use:     "statuses",
 	Aliases: []string{"st"},
@@ -311,6 +362,21 @@ var accountListEndorsementsSubcommand = &cobra.Command{
 	},
 }
 
+var accountNoteSubcommand = &cobra.Command{
+	Use:   "note [--set TEXT|--clear] ACCOUNT",
+	Short: "Display or set the private note about an account",
+	Long: `Display or set the private note about an account
+
+The note is only visible to you.  Without option, the relationship with
+the account (including the note) is displayed.`,
+	Example: `  madonctl account note Gargron@mastodon.social
+  madonctl account note --set "Met at FOSDEM" https://mastodon.social/@Gargron
+  madonctl account note --clear 1234`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return accountSubcommandsRunE(cmd.Name(), args)
+	},
+}
+
 var accountRelationshipsSubcommand = &cobra.Command{
 	Use:   "relationships --account-ids ACC1,ACC2...",
 	Short: "List relationships with the accounts",
@@ -384,28 +450,26 @@ func accountSubcommandsRunE(subcmd string, args []string) error {
 		return errors.New("too many account identifiers provided")
 	}
 
+	if opt.accountID != "" && !isAccountID(opt.accountID) {
+		// --account-id can also contain an address or an URL
+		opt.accountUID, opt.accountID = opt.accountID, ""
+	}
+
 	if userInArg {
 		// Is the argument an account ID?
-		if _, err := strconv.ParseInt(args[0], 10, 64); err == nil {
+		if isAccountID(args[0]) {
 			opt.accountID = args[0]
-		} else if strings.HasPrefix(args[0], "https://") || strings.HasPrefix(args[0], "http://") {
-			// That is not a remote UID scheme
-			opt.accountUID = args[0]
-		} else if subcmd == "follow" {
-			// For the follow API, got to be a remote UID...
-			opt.remoteUID = args[0]
-			// ... unless it's local (i.e. no '@' in the identifier)...
-			fid := strings.TrimLeft(args[0], "@")
-			if !strings.ContainsRune(fid, '@') {
-				opt.accountUID = args[0]
-				opt.remoteUID = ""
-			}
 		} else {
-			// Fall back to account UID
+			// Fall back to account UID (address or URL)
 			opt.accountUID = args[0]
 		}
 	}
 
+	if opt.remoteUID != "" {
+		// Remote accounts are resolved like the other accounts
+		opt.accountUID, opt.remoteUID = opt.remoteUID, ""
+	}
+
 	if opt.accountUID != "" {
 		if opt.accountID != "" {
 			return errors.New("cannot use both account ID and UID")
@@ -435,16 +499,10 @@ func accountSubcommandsRunE(subcmd string, args []string) error {
 			return errors.New("useless account ID")
 		}
 	case "follow", "unfollow":
-		// We need an account ID or a remote UID
-		if opt.accountID == "" && opt.remoteUID == "" {
+		// We need an account ID (remote UIDs have been resolved)
+		if opt.accountID == "" {
 			return errors.New("missing account ID or URI")
 		}
-		if opt.accountID != "" && opt.remoteUID != "" {
-			return errors.New("cannot use both account ID and URI")
-		}
-		if subcmd == "unfollow" && opt.accountID == "" {
-			return errors.New("unfollowing requires an account ID")
-		}
 	case "follow-requests":
 		if opt.list {
 			if opt.acceptFR || opt.rejectFR {
@@ -462,6 +520,13 @@ func accountSubcommandsRunE(subcmd string, args []string) error {
 				return errors.New("missing account ID")
 			}
 		}
+	case "note":
+		if opt.accountID == "" {
+			return errors.New("missing account ID")
+		}
+		if opt.clearNote && accountNoteFlags.Lookup("set").Changed {
+			return errors.New("incompatible options")
+		}
 	case "relationships":
 		if opt.accountID == "" && len(opt.accountIDs) == 0 {
 			return errors.New("missing account IDs")
@@ -477,6 +542,22 @@ func accountSubcommandsRunE(subcmd string, args []string) error {
 			return errors.New("missing parameter")
 		}
 	case "followers", "following", "statuses":
+		if opt.notFollowedBack && opt.mutual || opt.notFollowingBack && opt.mutual {
+			return errors.New("incompatible options")
+		}
+		if opt.inactive != "" {
+			if _, err := parseDuration(opt.inactive); err != nil {
+				return errors.Wrap(err, "invalid --inactive duration")
+			}
+		}
+		if opt.unfollow {
+			if !opt.notFollowingBack && !opt.mutual && opt.inactive == "" {
+				return errors.New("--unfollow requires a filter (--not-following-back, --mutual or --inactive)")
+			}
+			if opt.accountID != "" {
+				return errors.New("--unfollow can only be used for the current user")
+			}
+		}
 		// If the user's account ID is missing, get it
 		if opt.accountID == "" {
 			// Sign in now to look the user id up
@@ -532,23 +613,41 @@ func accountSubcommandsRunE(subcmd string, args []string) error {
 			account, err = gClient.GetCurrentAccount()
 		}
 		obj = account
+		if err == nil && opt.accountID != "" && getOutputFormat() == "plain" {
+			// The private note is only displayed with the plain output
+			// (it is not part of the account entity).
+			if rl, err := gExtClient.GetRelationships([]madon.ActivityID{opt.accountID}); err == nil && len(rl) == 1 && rl[0].Note != "" {
+				obj = &mastodon.AccountWithNote{Account: *account, PrivateNote: rl[0].Note}
+			}
+		}
 	case "search":
 		var accountList []madon.Account
 		accountList, err = gClient.SearchAccounts(strings.Join(args, " "), opt.following, limOpts)
 		obj = accountList
 	case "followers":
 		var accountList []madon.Account
-		accountList, err = gClient.GetAccountFollowers(opt.accountID, limOpts)
+		if opt.notFollowedBack || opt.mutual {
+			accountList, err = accountFollowsFilter(subcmd, opt.accountID)
+		} else {
+			accountList, err = gClient.GetAccountFollowers(opt.accountID, limOpts)
+		}
 		if opt.keep > 0 && len(accountList) > int(opt.keep) {
 			accountList = accountList[:opt.keep]
 		}
 		obj = accountList
 	case "following":
 		var accountList []madon.Account
-		accountList, err = gClient.GetAccountFollowing(opt.accountID, limOpts)
+		if opt.notFollowingBack || opt.mutual || opt.inactive != "" {
+			accountList, err = accountFollowsFilter(subcmd, opt.accountID)
+		} else {
+			accountList, err = gClient.GetAccountFollowing(opt.accountID, limOpts)
+		}
 		if opt.keep > 0 && len(accountList) > int(opt.keep) {
 			accountList = accountList[:opt.keep]
 		}
+		if err == nil && opt.unfollow {
+			accountList, err = accountBulkUnfollow(accountList)
+		}
 		obj = accountList
 	case "statuses":
 		var statusList []madon.Status
@@ -558,30 +657,26 @@ func accountSubcommandsRunE(subcmd string, args []string) error {
 		}
 		obj = statusList
 	case "follow", "unfollow":
-		var relationship *madon.Relationship
+		var relationship *mastodon.Relationship
 		if subcmd == "unfollow" {
-			relationship, err = gClient.UnfollowAccount(opt.accountID)
+			relationship, err = gExtClient.UnfollowAccount(opt.accountID)
 			obj = relationship
 			break
 		}
-		if opt.accountID == "" {
-			if opt.remoteUID != "" {
-				// Remote account
-				var account *madon.Account
-				account, err = gClient.FollowRemoteAccount(opt.remoteUID)
-				obj = account
-				break
-			}
-			return errors.New("error: no usable parameter")
-		}
-
-		// Locally-known account
-		var followReblogs *bool
+		var fp mastodon.FollowParams
 		if accountFollowFlags.Lookup("show-reblogs").Changed {
-			// Set followReblogs as it's been explicitly requested
-			followReblogs = &opt.reblogs
+			// Set Reblogs as it's been explicitly requested
+			fp.Reblogs = &opt.reblogs
+		}
+		if accountFollowFlags.Lookup("notify").Changed {
+			fp.Notify = &opt.notify
+		}
+		for _, l := range strings.Split(opt.languages, ",") {
+			if l = strings.TrimSpace(l); l != "" {
+				fp.Languages = append(fp.Languages, l)
+			}
 		}
-		relationship, err = gClient.FollowAccount(opt.accountID, followReblogs)
+		relationship, err = gExtClient.FollowAccount(opt.accountID, fp)
 		obj = relationship
 	case "follow-requests":
 		if opt.list {
@@ -610,31 +705,36 @@ func accountSubcommandsRunE(subcmd string, args []string) error {
 			err = gClient.FollowRequestAuthorize(opt.accountID, !opt.rejectFR)
 		}
 	case "block", "unblock":
-		var relationship *madon.Relationship
+		var relationship *mastodon.Relationship
 		if subcmd == "unblock" {
-			relationship, err = gClient.UnblockAccount(opt.accountID)
+			relationship, err = gExtClient.UnblockAccount(opt.accountID)
 		} else {
-			relationship, err = gClient.BlockAccount(opt.accountID)
+			relationship, err = gExtClient.BlockAccount(opt.accountID)
 		}
 		obj = relationship
 	case "mute", "unmute":
-		var relationship *madon.Relationship
+		var relationship *mastodon.Relationship
 		if subcmd == "unmute" {
-			relationship, err = gClient.UnmuteAccount(opt.accountID)
+			relationship, err = gExtClient.UnmuteAccount(opt.accountID)
 		} else {
-			var muteNotif *bool
+			var mp mastodon.MuteParams
 			if accountMuteFlags.Lookup("notifications").Changed {
-				muteNotif = &opt.muteNotifications
+				mp.Notifications = &opt.muteNotifications
 			}
-			relationship, err = gClient.MuteAccount(opt.accountID, muteNotif)
+			if opt.muteDuration != "" {
+				if mp.Duration, err = parseDuration(opt.muteDuration); err != nil || mp.Duration < time.Second {
+					return errors.Errorf("invalid mute duration '%s'", opt.muteDuration)
+				}
+			}
+			relationship, err = gExtClient.MuteAccount(opt.accountID, mp)
 		}
 		obj = relationship
 	case "pin", "unpin":
-		var relationship *madon.Relationship
+		var relationship *mastodon.Relationship
 		if subcmd == "unpin" {
-			relationship, err = gClient.UnpinAccount(opt.accountID)
+			relationship, err = gExtClient.UnpinAccount(opt.accountID)
 		} else {
-			relationship, err = gClient.PinAccount(opt.accountID)
+			relationship, err = gExtClient.PinAccount(opt.accountID)
 		}
 		obj = relationship
 	case "favourites":
@@ -652,8 +752,8 @@ func accountSubcommandsRunE(subcmd string, args []string) error {
 		}
 		obj = accountList
 	case "mutes":
-		var accountList []madon.Account
-		accountList, err = gClient.GetMutedAccounts(limOpts)
+		var accountList []mastodon.Account
+		accountList, err = gExtClient.GetMutedAccounts(limOpts)
 		if opt.keep > 0 && len(accountList) > int(opt.keep) {
 			accountList = accountList[:opt.keep]
 		}
@@ -667,19 +767,27 @@ func accountSubcommandsRunE(subcmd string, args []string) error {
 		obj = accountList
 	case "relationships":
 		var ids []madon.ActivityID
-		ids, err = splitIDs(opt.accountIDs)
-		if err != nil {
-			return errors.New("cannot parse account IDs")
-		}
 		if opt.accountID != "" { // Allow --account-id
 			ids = []madon.ActivityID{opt.accountID}
+		} else if ids, err = resolveAccountIDs(opt.accountIDs); err != nil {
+			break
 		}
 		if len(ids) < 1 {
 			return errors.New("missing account IDs")
 		}
-		var relationships []madon.Relationship
-		relationships, err = gClient.GetAccountRelationships(ids)
+		var relationships []mastodon.Relationship
+		relationships, err = gExtClient.GetRelationships(ids)
 		obj = relationships
+	case "note":
+		if !opt.clearNote && !accountNoteFlags.Lookup("set").Changed {
+			var relationships []mastodon.Relationship
+			relationships, err = gExtClient.GetRelationships([]madon.ActivityID{opt.accountID})
+			obj = relationships
+			break
+		}
+		var relationship *mastodon.Relationship
+		relationship, err = gExtClient.SetAccountNote(opt.accountID, opt.privateNote)
+		obj = relationship
 	case "reports":
 		if opt.list {
 			var reports []madon.Report
@@ -692,9 +800,8 @@ func accountSubcommandsRunE(subcmd string, args []string) error {
 		}
 		// Send a report
 		var ids []madon.ActivityID
-		ids, err = splitIDs(opt.statusIDs)
-		if err != nil {
-			return errors.New("cannot parse status IDs")
+		if ids, err = resolveStatusIDs(opt.statusIDs); err != nil {
+			break
 		}
 		if len(ids) < 1 {
 			return errors.New("missing status IDs")
@@ -795,45 +902,17 @@ func accountSubcommandsRunE(subcmd string, args []string) error {
 }
 
 // accountLookupUser tries to find a (single) user matching 'user'
-// If the user is an HTTP URL, it will use the search API, else
-// it will use the accounts/search API.
+// The user can be an account address (user@domain) or an HTTP URL; see
+// accountResolver.
 func accountLookupUser(user string) (madon.ActivityID, error) {
-	var accID madon.ActivityID
-
-	if strings.HasPrefix(user, "https://") || strings.HasPrefix(user, "http://") {
-		res, err := gClient.Search(user, true)
-		if err != nil {
-			return "", err
-		}
-		if res != nil {
-			if len(res.Accounts) > 1 {
-				return "", errors.New("several results")
-			}
-			if len(res.Accounts) == 1 {
-				accID = res.Accounts[0].ID
-			}
-		}
-	} else {
-		// Remove leading '@'
-		user = strings.TrimLeft(user, "@")
-
-		accList, err := gClient.SearchAccounts(user, false, &madon.LimitParams{Limit: 2})
-		if err != nil {
-			return "", err
-		}
-		for _, u := range accList {
-			if u.Acct == user {
-				accID = u.ID
-				break
-			}
-		}
-	}
-
-	if accID == "" {
-		return "", errors.New("user not found")
+	r := newAccountResolver(gClient)
+	accID, err := r.resolveID(user)
+	r.cache.save()
+	if err != nil {
+		return "", err
 	}
 	if verbose {
-		errPrint("User '%s' is account ID %d", user, user)
+		errPrint("User '%s' is account ID %s", user, accID)
 	}
 	return accID, nil
 }
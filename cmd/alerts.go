@@ -0,0 +1,252 @@
+// Copyright © 2026 Mikael Berthe <mikael@lilotux.net>
+//
+// Licensed under the MIT license.
+// Please see the LICENSE file is this directory.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/mattn/go-isatty"
+	"github.com/pkg/errors"
+
+	"github.com/McKael/madon/v3"
+	"github.com/McKael/madonctl/v3/printer/colors"
+	"github.com/McKael/madonctl/v3/printer/html2text"
+)
+
+// alertRule is a stream alert rule (see --alert-rules)
+// All the regular expressions that are set must match.
+type alertRule struct {
+	Name     string `json:"name"`
+	Text     string `json:"text,omitempty"`     // Status text (and content warning)
+	Account  string `json:"account,omitempty"`  // Account address (user@domain)
+	Domain   string `json:"domain,omitempty"`   // Account domain
+	Tags     string `json:"tags,omitempty"`     // Any of the hashtags
+	Cooldown string `json:"cooldown,omitempty"` // Minimum delay between two alerts
+	Action   string `json:"action,omitempty"`   // print (default), command or file
+	Command  string `json:"command,omitempty"`  // Shell command (command action)
+	File     string `json:"file,omitempty"`     // Output file (file action)
+
+	text, account, domain, tags *regexp.Regexp
+	cooldown                    time.Duration
+	last                        time.Time // Last alert
+}
+
+// alertRecord is the JSON representation of an alert (file action and
+// --events-json)
+type alertRecord struct {
+	Time     time.Time     `json:"time"`
+	Rule     string        `json:"rule"`
+	StatusID string        `json:"status_id"`
+	URL      string        `json:"url,omitempty"`
+	Account  string        `json:"account,omitempty"`
+	Text     string        `json:"text"`
+	Status   *madon.Status `json:"status,omitempty"`
+}
+
+// loadAlertRules reads an alert rule file (YAML or JSON)
+func loadAlertRules(filename string) ([]*alertRule, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read alert rule file")
+	}
+
+	var ruleFile struct {
+		Rules []*alertRule `json:"rules"`
+	}
+	if err := yaml.Unmarshal(data, &ruleFile); err != nil {
+		return nil, errors.Wrap(err, "cannot parse alert rule file")
+	}
+	if len(ruleFile.Rules) == 0 {
+		return nil, errors.New("no alert rule found")
+	}
+
+	compile := func(expr string) (*regexp.Regexp, error) {
+		if expr == "" {
+			return nil, nil
+		}
+		return regexp.Compile(expr)
+	}
+
+	for i, r := range ruleFile.Rules {
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rule%d", i+1)
+		}
+		if r.text, err = compile(r.Text); err == nil {
+			if r.account, err = compile(r.Account); err == nil {
+				if r.domain, err = compile(r.Domain); err == nil {
+					r.tags, err = compile(r.Tags)
+				}
+			}
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "alert rule '%s'", r.Name)
+		}
+		if r.text == nil && r.account == nil && r.domain == nil && r.tags == nil {
+			return nil, errors.Errorf("alert rule '%s' has no condition", r.Name)
+		}
+		if r.Cooldown != "" {
+			if r.cooldown, err = parseDuration(r.Cooldown); err != nil {
+				return nil, errors.Wrapf(err, "alert rule '%s': invalid cooldown", r.Name)
+			}
+		}
+		switch r.Action {
+		case "":
+			r.Action = "print"
+		case "print":
+		case "command":
+			if r.Command == "" {
+				return nil, errors.Errorf("alert rule '%s': missing command", r.Name)
+			}
+		case "file":
+			if r.File == "" {
+				return nil, errors.Errorf("alert rule '%s': missing file", r.Name)
+			}
+		default:
+			return nil, errors.Errorf("alert rule '%s': unknown action '%s'", r.Name, r.Action)
+		}
+	}
+	return ruleFile.Rules, nil
+}
+
+// match returns true if the status matches the rule
+// The text is the plain-text version of the status content.
+func (r *alertRule) match(s *madon.Status, text string) bool {
+	if r.text != nil && !r.text.MatchString(text) {
+		return false
+	}
+	if r.account != nil || r.domain != nil {
+		if s.Account == nil {
+			return false
+		}
+		address, domain := accountAddress(s.Account)
+		if r.account != nil && !r.account.MatchString(address) {
+			return false
+		}
+		if r.domain != nil && !r.domain.MatchString(domain) {
+			return false
+		}
+	}
+	if r.tags != nil {
+		for _, t := range s.Tags {
+			if r.tags.MatchString(t.Name) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// statusText returns the plain-text version of the status content, prefixed
+// with the content warning if there is one
+func statusText(s *madon.Status) string {
+	text, err := html2text.Textify(s.Content)
+	if err != nil {
+		text = s.Content
+	}
+	if s.SpoilerText != "" {
+		text = s.SpoilerText + "\n" + text
+	}
+	return text
+}
+
+// checkAlerts returns the rules matching the status (the reblogged status
+// is used for reblogs).  The cooldown delays are enforced.
+func checkAlerts(rules []*alertRule, s *madon.Status) ([]*alertRule, string) {
+	if s.Reblog != nil {
+		s = s.Reblog
+	}
+	text := statusText(s)
+
+	var matching []*alertRule
+	now := time.Now()
+	for _, r := range rules {
+		if !r.match(s, text) {
+			continue
+		}
+		if r.cooldown > 0 && now.Sub(r.last) < r.cooldown {
+			if verbose {
+				errPrint("Alert '%s' skipped (cooldown)", r.Name)
+			}
+			continue
+		}
+		r.last = now
+		matching = append(matching, r)
+	}
+	return matching, text
+}
+
+// newAlertRecord returns the alert record for a status
+func newAlertRecord(r *alertRule, s *madon.Status, text string) alertRecord {
+	if s.Reblog != nil {
+		s = s.Reblog
+	}
+	ar := alertRecord{Time: time.Now(), Rule: r.Name, StatusID: s.ID, URL: s.URL, Text: text}
+	if s.Account != nil {
+		ar.Account, _ = accountAddress(s.Account)
+	}
+	return ar
+}
+
+// appendAlert writes an alert to the rule output file (JSON lines)
+func appendAlert(r *alertRule, ar alertRecord) error {
+	line, err := json.Marshal(ar)
+	if err != nil {
+		return errors.Wrap(err, "cannot encode alert")
+	}
+	f, err := os.OpenFile(r.File, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return errors.Wrap(err, "cannot open alert file")
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return errors.Wrap(err, "cannot write alert")
+	}
+	return nil
+}
+
+// alertColors returns true if the alerts should be highlighted
+func alertColors() bool {
+	switch getColorMode() {
+	case "on":
+		return true
+	case "off":
+		return false
+	}
+	return isatty.IsTerminal(os.Stdout.Fd())
+}
+
+// printAlert displays an alert; the text matching the rule is highlighted
+// if colors are enabled.
+func printAlert(w io.Writer, r *alertRule, ar alertRecord, color bool) {
+	text := ar.Text
+	header := fmt.Sprintf("*** Alert [%s]: %s", r.Name, ar.Account)
+	if ar.URL != "" {
+		header += " " + ar.URL
+	}
+	if color {
+		hl, _ := colors.ANSICodeString("red,,bold")
+		reset, _ := colors.ANSICodeString("reset")
+		header = hl + header + reset
+		if r.text != nil {
+			text = r.text.ReplaceAllStringFunc(text, func(m string) string {
+				return hl + m + reset
+			})
+		}
+	}
+	fmt.Fprintln(w, header)
+	for _, l := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		fmt.Fprintln(w, "  "+l)
+	}
+}
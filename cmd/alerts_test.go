@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/McKael/madon/v3"
+)
+
+func TestAlertRuleMatch(t *testing.T) {
+	status := &madon.Status{
+		Account: &madon.Account{Acct: "alice@example.com"},
+		Tags:    []madon.Tag{{Name: "golang"}, {Name: "mastodon"}},
+	}
+	local := &madon.Status{
+		Account: &madon.Account{Acct: "bob", URL: "https://social.example.org/@bob"},
+	}
+	text := "New madonctl release"
+
+	re := regexp.MustCompile
+	tests := []struct {
+		name     string
+		rule     alertRule
+		status   *madon.Status
+		expected bool
+	}{
+		{"text", alertRule{text: re(`(?i)\bmadonctl\b`)}, status, true},
+		{"text mismatch", alertRule{text: re(`rust`)}, status, false},
+		{"account", alertRule{account: re(`^alice@`)}, status, true},
+		{"domain", alertRule{domain: re(`^example\.com$`)}, status, true},
+		{"local account domain", alertRule{domain: re(`^social\.example\.org$`)}, local, true},
+		{"local account address", alertRule{account: re(`^bob@social\.example\.org$`)}, local, true},
+		{"any tag", alertRule{tags: re(`^mastodon$`)}, status, true},
+		{"no tag", alertRule{tags: re(`^mastodon$`)}, local, false},
+		{"all conditions", alertRule{text: re(`release`), domain: re(`example\.com`), tags: re(`go`)}, status, true},
+		{"one condition fails", alertRule{text: re(`release`), domain: re(`example\.net`)}, status, false},
+		{"no account", alertRule{account: re(`.`)}, &madon.Status{}, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, tt.rule.match(tt.status, text), tt.name)
+	}
+}
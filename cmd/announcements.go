@@ -0,0 +1,186 @@
+// Copyright © 2026 Mikael Berthe <mikael@lilotux.net>
+//
+// Licensed under the MIT license.
+// Please see the LICENSE file is this directory.
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/McKael/madon/v3"
+	"github.com/McKael/madonctl/v3/mastodon"
+)
+
+var announcementsOpts struct {
+	announcementID madon.ActivityID
+	withDismissed  bool
+
+	keep uint
+}
+
+// announcementsCmd represents the announcements command
+var announcementsCmd = &cobra.Command{
+	Use:     "announcements",
+	Aliases: []string{"announcement"},
+	Short:   "Display, dismiss and react to instance announcements",
+	RunE:    announcementsGetRunE, // Defaults to list
+	Example: `  madonctl announcements
+  madonctl announcements list --with-dismissed
+  madonctl announcements dismiss --announcement-id 8
+  madonctl announcements react --announcement-id 8 🎉
+  madonctl announcements react --announcement-id 8 blobcat
+  madonctl announcements unreact --announcement-id 8 🎉`,
+}
+
+func init() {
+	RootCmd.AddCommand(announcementsCmd)
+
+	// Subcommands
+	announcementsCmd.AddCommand(announcementsSubcommands...)
+
+	announcementsCmd.PersistentFlags().StringVar(&announcementsOpts.announcementID, "announcement-id", "", "Announcement ID")
+
+	announcementsCmd.Flags().BoolVar(&announcementsOpts.withDismissed, "with-dismissed", false, "Include dismissed announcements")
+	announcementsCmd.Flags().UintVarP(&announcementsOpts.keep, "keep", "k", 0, "Limit number of results")
+	announcementsGetSubcommand.Flags().BoolVar(&announcementsOpts.withDismissed, "with-dismissed", false, "Include dismissed announcements")
+	announcementsGetSubcommand.Flags().UintVarP(&announcementsOpts.keep, "keep", "k", 0, "Limit number of results")
+}
+
+var announcementsSubcommands = []*cobra.Command{
+	announcementsGetSubcommand,
+	announcementsDismissSubcommand,
+	announcementsReactSubcommand,
+	announcementsUnreactSubcommand,
+}
+
+var announcementsGetSubcommand = &cobra.Command{
+	Use:   "list",
+	Short: "Display the announcements (default subcommand)",
+	Long: `Display the list of active instance announcements.
+
+If an announcement ID is provided, only this announcement is displayed.`,
+	Aliases: []string{"ls", "get", "display", "show"},
+	RunE:    announcementsGetRunE,
+}
+
+var announcementsDismissSubcommand = &cobra.Command{
+	Use:     "dismiss --announcement-id ID",
+	Short:   "Mark an announcement as read",
+	Aliases: []string{"read"},
+	RunE:    announcementsSetRunE,
+}
+
+var announcementsReactSubcommand = &cobra.Command{
+	Use:     "react --announcement-id ID EMOJI",
+	Short:   "Add an emoji reaction to an announcement",
+	Long:    `Add a reaction (unicode emoji or custom emoji shortcode) to an announcement.`,
+	Aliases: []string{"add-reaction"},
+	RunE:    announcementsSetRunE,
+}
+
+var announcementsUnreactSubcommand = &cobra.Command{
+	Use:     "unreact --announcement-id ID EMOJI",
+	Short:   "Remove an emoji reaction from an announcement",
+	Aliases: []string{"remove-reaction"},
+	RunE:    announcementsSetRunE,
+}
+
+func announcementsGetRunE(cmd *cobra.Command, args []string) error {
+	opt := announcementsOpts
+
+	// We need to be logged in
+	if err := madonInit(true); err != nil {
+		return err
+	}
+
+	var obj interface{}
+	var err error
+
+	var announcementList []mastodon.Announcement
+	announcementList, err = gExtClient.GetAnnouncements(opt.withDismissed || opt.announcementID != "")
+
+	if opt.announcementID != "" { // Display a specific announcement
+		var al []mastodon.Announcement
+		for _, a := range announcementList {
+			if a.ID == opt.announcementID {
+				al = append(al, a)
+				break
+			}
+		}
+		if err == nil && len(al) == 0 {
+			err = errors.New("announcement not found")
+		}
+		announcementList = al
+	}
+
+	if opt.keep > 0 && len(announcementList) > int(opt.keep) {
+		announcementList = announcementList[:opt.keep]
+	}
+
+	obj = announcementList
+
+	if err != nil {
+		errPrint("Error: %s", err.Error())
+		os.Exit(1)
+	}
+	if obj == nil {
+		return nil
+	}
+
+	p, err := getPrinter()
+	if err != nil {
+		errPrint("Error: %v", err)
+		os.Exit(1)
+	}
+	return p.printObj(obj)
+}
+
+func announcementsSetRunE(cmd *cobra.Command, args []string) error {
+	opt := announcementsOpts
+
+	if opt.announcementID == "" {
+		return errors.New("missing announcement ID")
+	}
+
+	var reaction string
+	switch cmd.Name() {
+	case "react", "unreact":
+		if len(args) != 1 {
+			return errors.New("wrong usage: please provide a single emoji")
+		}
+		reaction = args[0]
+	default:
+		if len(args) > 0 {
+			return errors.New("too many arguments")
+		}
+	}
+
+	// We need to be logged in
+	if err := madonInit(true); err != nil {
+		return err
+	}
+
+	var err error
+
+	switch cmd.Name() {
+	case "dismiss":
+		err = gExtClient.DismissAnnouncement(opt.announcementID)
+	case "react":
+		err = gExtClient.AddAnnouncementReaction(opt.announcementID, reaction)
+	case "unreact":
+		err = gExtClient.RemoveAnnouncementReaction(opt.announcementID, reaction)
+	default:
+		// Shouldn't happen.  If it does, might be an unrecognized alias.
+		return errors.New("announcementsSetRunE: internal error")
+	}
+
+	if err != nil {
+		errPrint("Error: %s", err.Error())
+		os.Exit(1)
+	}
+	return nil
+}
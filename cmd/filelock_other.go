@@ -0,0 +1,40 @@
+// Copyright © 2026 Mikael Berthe <mikael@lilotux.net>
+//
+// Licensed under the MIT license.
+// Please see the LICENSE file is this directory.
+
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// fileLock is an exclusive lock on a file
+// On this platform the lock file is created exclusively and removed when
+// the lock is released; a stale lock file has to be removed manually.
+type fileLock struct {
+	path string
+}
+
+// lockFile takes an exclusive lock on the file path
+// It fails immediately if the lock is held by another process.
+func lockFile(path string) (*fileLock, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		if os.IsExist(err) {
+			return nil, errors.Errorf("%s is locked by another process (remove the file if it is stale)", path)
+		}
+		return nil, err
+	}
+	f.Close()
+	return &fileLock{path: path}, nil
+}
+
+// unlock releases the lock
+func (l *fileLock) unlock() {
+	os.Remove(l.path)
+}
@@ -0,0 +1,612 @@
+// Copyright © 2026 Mikael Berthe <mikael@lilotux.net>
+//
+// Licensed under the MIT license.
+// Please see the LICENSE file is this directory.
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/McKael/madon/v3"
+	"github.com/McKael/madonctl/v3/mastodon"
+	"github.com/McKael/madonctl/v3/printer"
+)
+
+var filtersOpts struct {
+	filterID madon.ActivityID
+
+	// Used for create/update
+	title          string
+	context        string
+	action         string
+	expiresIn      string
+	keywords       []string
+	removeKeywords []string
+	wholeWord      bool
+
+	// Used for import
+	updateExisting bool
+
+	keep uint
+}
+
+// filterExport is the representation of a filter used by the
+// export and import subcommands
+type filterExport struct {
+	Title     string                `json:"title"`
+	Context   []string              `json:"context"`
+	Action    string                `json:"action,omitempty"`
+	ExpiresAt *time.Time            `json:"expires_at,omitempty"`
+	Keywords  []filterExportKeyword `json:"keywords,omitempty"`
+}
+
+type filterExportKeyword struct {
+	Keyword   string `json:"keyword"`
+	WholeWord bool   `json:"whole_word,omitempty"`
+}
+
+// filtersCmd represents the filters command
+var filtersCmd = &cobra.Command{
+	Use:     "filters",
+	Aliases: []string{"filter"},
+	Short:   "Manage server-side filters",
+	Long: `Manage server-side filters
+
+Filters contain a list of keywords and apply in one or several contexts
+(home, notifications, public, thread, account).
+The filter action is either "warn" (the status is displayed with a warning)
+or "hide" (the status is not displayed at all).`,
+	RunE: filtersGetRunE, // Defaults to list
+	Example: `  madonctl filters list
+  madonctl filters show --filter-id 12
+  madonctl filters create --title Spoilers --context home,public --keyword dragons --keyword GoT
+  madonctl filters create --title Cats --context home,thread --action hide --whole-word --keyword cat --expires-in 7d
+  madonctl filters update --filter-id 12 --add-keyword winter --remove-keyword GoT
+  madonctl filters update --filter-id 12 --action hide --expires-in 0
+  madonctl filters delete --filter-id 12
+  madonctl filters export > filters.yaml
+  madonctl filters import filters.yaml`,
+}
+
+func init() {
+	RootCmd.AddCommand(filtersCmd)
+
+	// Subcommands
+	filtersCmd.AddCommand(filtersSubcommands...)
+
+	filtersCmd.PersistentFlags().StringVar(&filtersOpts.filterID, "filter-id", "", "Filter ID")
+
+	filtersCmd.Flags().UintVarP(&filtersOpts.keep, "keep", "k", 0, "Limit number of results")
+	filtersGetSubcommand.Flags().UintVarP(&filtersOpts.keep, "keep", "k", 0, "Limit number of results")
+
+	filtersCreateSubcommand.Flags().StringVar(&filtersOpts.title, "title", "", "Filter title")
+	filtersCreateSubcommand.Flags().StringVar(&filtersOpts.context, "context", "", "Comma-separated list of contexts (home, notifications, public, thread, account)")
+	filtersCreateSubcommand.Flags().StringVar(&filtersOpts.action, "action", "", "Filter action (warn|hide)")
+	filtersCreateSubcommand.Flags().StringVar(&filtersOpts.expiresIn, "expires-in", "", "Filter lifetime (e.g. 12h, 30d; 0 for no expiration)")
+	filtersCreateSubcommand.Flags().StringArrayVar(&filtersOpts.keywords, "keyword", nil, "Filter keyword")
+	filtersCreateSubcommand.Flags().BoolVar(&filtersOpts.wholeWord, "whole-word", false, "Keywords should match whole words")
+
+	filtersUpdateSubcommand.Flags().StringVar(&filtersOpts.title, "title", "", "Filter title")
+	filtersUpdateSubcommand.Flags().StringVar(&filtersOpts.context, "context", "", "Comma-separated list of contexts (home, notifications, public, thread, account)")
+	filtersUpdateSubcommand.Flags().StringVar(&filtersOpts.action, "action", "", "Filter action (warn|hide)")
+	filtersUpdateSubcommand.Flags().StringVar(&filtersOpts.expiresIn, "expires-in", "", "Filter lifetime (e.g. 12h, 30d; 0 for no expiration)")
+	filtersUpdateSubcommand.Flags().StringArrayVar(&filtersOpts.keywords, "add-keyword", nil, "Add a keyword")
+	filtersUpdateSubcommand.Flags().StringArrayVar(&filtersOpts.removeKeywords, "remove-keyword", nil, "Remove a keyword")
+	filtersUpdateSubcommand.Flags().BoolVar(&filtersOpts.wholeWord, "whole-word", false, "New keywords should match whole words")
+
+	filtersImportSubcommand.Flags().BoolVar(&filtersOpts.updateExisting, "update-existing", false, "Update filters with the same title")
+}
+
+var filtersSubcommands = []*cobra.Command{
+	filtersGetSubcommand,
+	filtersCreateSubcommand,
+	filtersUpdateSubcommand,
+	filtersDeleteSubcommand,
+	filtersExportSubcommand,
+	filtersImportSubcommand,
+}
+
+var filtersGetSubcommand = &cobra.Command{
+	Use:     "list",
+	Short:   "Display the filters (default subcommand)",
+	Long:    `Display the list of filters, or a single filter if a filter ID is provided.`,
+	Aliases: []string{"ls", "get", "display", "show"},
+	RunE:    filtersGetRunE,
+}
+
+var filtersCreateSubcommand = &cobra.Command{
+	Use:   "create --title TITLE --context CONTEXT[,CONTEXT...] [--keyword KEYWORD...]",
+	Short: "Create a filter",
+	RunE:  filtersSetDeleteRunE,
+}
+
+var filtersUpdateSubcommand = &cobra.Command{
+	Use:   "update --filter-id N",
+	Short: "Update a filter",
+	Long: `Update a filter
+
+Only the specified settings are updated.
+Keywords can be added with --add-keyword and removed with --remove-keyword.`,
+	RunE: filtersSetDeleteRunE,
+}
+
+var filtersDeleteSubcommand = &cobra.Command{
+	Use:     "delete --filter-id N",
+	Short:   "Delete a filter",
+	Aliases: []string{"rm", "del"},
+	RunE:    filtersSetDeleteRunE,
+}
+
+var filtersExportSubcommand = &cobra.Command{
+	Use:   "export",
+	Short: "Export filters",
+	Long: `Export filters
+
+The filters are exported in YAML (unless another output format is requested),
+so that they can be imported with the import subcommand (for example into
+another account).`,
+	RunE: filtersExportRunE,
+}
+
+var filtersImportSubcommand = &cobra.Command{
+	Use:   "import FILE",
+	Short: "Import filters",
+	Long: `Import filters
+
+Import filters from a file created with the export subcommand.
+Use "-" to read from the standard input.
+
+Filters with the same title as an existing filter are skipped, unless
+--update-existing is used: in that case the existing filter context, action
+and expiration are updated and the missing keywords are added.
+Expired filters are skipped.`,
+	RunE: filtersImportRunE,
+}
+
+func filtersGetRunE(cmd *cobra.Command, args []string) error {
+	opt := filtersOpts
+
+	// We need to be logged in
+	if err := madonInit(true); err != nil {
+		return err
+	}
+
+	var obj interface{}
+	var err error
+
+	if opt.filterID != "" {
+		var filter *mastodon.Filter
+		filter, err = gExtClient.GetFilter(opt.filterID)
+		obj = filter
+	} else {
+		var filterList []mastodon.Filter
+		filterList, err = gExtClient.GetFilters()
+
+		if opt.keep > 0 && len(filterList) > int(opt.keep) {
+			filterList = filterList[:opt.keep]
+		}
+		obj = filterList
+	}
+
+	if err != nil {
+		errPrint("Error: %s", err.Error())
+		os.Exit(1)
+	}
+	if obj == nil {
+		return nil
+	}
+
+	p, err := getPrinter()
+	if err != nil {
+		errPrint("Error: %v", err)
+		os.Exit(1)
+	}
+	return p.printObj(obj)
+}
+
+func filtersSetDeleteRunE(cmd *cobra.Command, args []string) error {
+	const (
+		actionUnknown = iota
+		actionCreate
+		actionUpdate
+		actionDelete
+	)
+
+	var action int
+	opt := filtersOpts
+
+	switch cmd.Name() {
+	case "create":
+		if opt.filterID != "" {
+			return errors.New("filter ID should not be provided with create")
+		}
+		if opt.title == "" {
+			return errors.New("the filter title is required")
+		}
+		if opt.context == "" {
+			return errors.New("the filter context is required")
+		}
+		action = actionCreate
+	case "update":
+		action = actionUpdate
+	case "delete":
+		action = actionDelete
+	}
+
+	// Additionnal checks
+	if action == actionUnknown {
+		// Shouldn't happen.  If it does, might be an unrecognized alias.
+		return errors.New("filtersSetDeleteRunE: internal error")
+	}
+
+	if action != actionCreate && opt.filterID == "" {
+		return errors.New("filter ID is required")
+	}
+
+	var params mastodon.FilterParams
+	change := false
+
+	if action != actionDelete {
+		flags := cmd.Flags()
+		if flags.Lookup("title").Changed {
+			if opt.title == "" {
+				return errors.New("the filter title cannot be empty")
+			}
+			params.Title = &opt.title
+			change = true
+		}
+		if flags.Lookup("context").Changed {
+			ctx, err := splitFilterContexts(opt.context)
+			if err != nil {
+				return err
+			}
+			params.Context = ctx
+			change = true
+		}
+		if flags.Lookup("action").Changed {
+			if err := checkFilterAction(opt.action); err != nil {
+				return err
+			}
+			params.FilterAction = &opt.action
+			change = true
+		}
+		if flags.Lookup("expires-in").Changed {
+			var expiresIn int64
+			if opt.expiresIn != "0" {
+				d, err := parseDuration(opt.expiresIn)
+				if err != nil {
+					return errors.Wrap(err, "cannot parse expiration delay")
+				}
+				expiresIn = int64(d.Seconds())
+			}
+			params.ExpiresIn = &expiresIn
+			change = true
+		}
+		for _, k := range opt.keywords {
+			if k == "" {
+				return errors.New("empty keyword")
+			}
+			params.Keywords = append(params.Keywords, mastodon.FilterKeywordParams{
+				Keyword:   k,
+				WholeWord: opt.wholeWord,
+			})
+			change = true
+		}
+	}
+
+	if action == actionUpdate && !change && len(opt.removeKeywords) == 0 {
+		return errors.New("missing parameters")
+	}
+
+	// Log in
+	if err := madonInit(true); err != nil {
+		return err
+	}
+
+	var obj interface{}
+	var err error
+	var filter *mastodon.Filter
+
+	switch action {
+	case actionCreate:
+		filter, err = gExtClient.CreateFilter(params)
+		obj = filter
+	case actionUpdate:
+		if len(opt.removeKeywords) > 0 {
+			// We need the keyword IDs
+			filter, err = gExtClient.GetFilter(opt.filterID)
+			if err != nil {
+				break
+			}
+			var kp []mastodon.FilterKeywordParams
+			kp, err = filterKeywordsToRemove(filter, opt.removeKeywords)
+			if err != nil {
+				break
+			}
+			params.Keywords = append(params.Keywords, kp...)
+		}
+		filter, err = gExtClient.UpdateFilter(opt.filterID, params)
+		obj = filter
+	case actionDelete:
+		err = gExtClient.DeleteFilter(opt.filterID)
+		obj = nil
+	}
+
+	if err != nil {
+		errPrint("Error: %s", err.Error())
+		os.Exit(1)
+	}
+	if obj == nil {
+		return nil
+	}
+
+	p, err := getPrinter()
+	if err != nil {
+		errPrint("Error: %v", err)
+		os.Exit(1)
+	}
+	return p.printObj(obj)
+}
+
+func filtersExportRunE(cmd *cobra.Command, args []string) error {
+	opt := filtersOpts
+
+	// We need to be logged in
+	if err := madonInit(true); err != nil {
+		return err
+	}
+
+	var filterList []mastodon.Filter
+	var err error
+
+	if opt.filterID != "" {
+		var filter *mastodon.Filter
+		filter, err = gExtClient.GetFilter(opt.filterID)
+		if filter != nil {
+			filterList = []mastodon.Filter{*filter}
+		}
+	} else {
+		filterList, err = gExtClient.GetFilters()
+	}
+
+	if err != nil {
+		errPrint("Error: %s", err.Error())
+		os.Exit(1)
+	}
+
+	exportList := []filterExport{}
+	for _, f := range filterList {
+		fe := filterExport{
+			Title:     f.Title,
+			Context:   f.Context,
+			Action:    f.FilterAction,
+			ExpiresAt: f.ExpiresAt,
+		}
+		for _, k := range f.Keywords {
+			fe.Keywords = append(fe.Keywords, filterExportKeyword{
+				Keyword:   k.Keyword,
+				WholeWord: k.WholeWord,
+			})
+		}
+		exportList = append(exportList, fe)
+	}
+
+	var p printer.ResourcePrinter
+	if getOutputFormat() == "plain" {
+		p, err = printer.NewPrinterYAML(nil)
+	} else {
+		p, err = getPrinter()
+	}
+	if err != nil {
+		errPrint("Error: %v", err)
+		os.Exit(1)
+	}
+	return p.PrintObj(exportList, nil, "")
+}
+
+func filtersImportRunE(cmd *cobra.Command, args []string) error {
+	opt := filtersOpts
+
+	if len(args) != 1 {
+		return errors.New("wrong usage: please provide a single file name")
+	}
+
+	var data []byte
+	var err error
+	if args[0] == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(args[0])
+	}
+	if err != nil {
+		return errors.Wrap(err, "cannot read filter file")
+	}
+
+	var importList []filterExport
+	if err := yaml.Unmarshal(data, &importList); err != nil {
+		return errors.Wrap(err, "cannot parse filter file")
+	}
+
+	// Check the filters before doing anything (the context aliases are
+	// replaced with the API names)
+	for i, fe := range importList {
+		if fe.Title == "" {
+			return errors.New("filter with empty title")
+		}
+		ctx, err := splitFilterContexts(strings.Join(fe.Context, ","))
+		if err != nil {
+			return errors.Wrapf(err, "filter '%s'", fe.Title)
+		}
+		importList[i].Context = ctx
+		if fe.Action != "" {
+			if err := checkFilterAction(fe.Action); err != nil {
+				return errors.Wrapf(err, "filter '%s'", fe.Title)
+			}
+		}
+	}
+
+	// We need to be logged in
+	if err := madonInit(true); err != nil {
+		return err
+	}
+
+	currentFilters, err := gExtClient.GetFilters()
+	if err != nil {
+		errPrint("Error: %s", err.Error())
+		os.Exit(1)
+	}
+	existing := make(map[string]*mastodon.Filter)
+	for i, f := range currentFilters {
+		existing[f.Title] = &currentFilters[i]
+	}
+
+	var results []mastodon.Filter
+	var failures int
+
+	for _, fe := range importList {
+		fe := fe
+		var params mastodon.FilterParams
+		params.Context = fe.Context
+		if fe.Action != "" {
+			params.FilterAction = &fe.Action
+		}
+
+		current := existing[fe.Title]
+		if current != nil && !opt.updateExisting {
+			errPrint("Skipping filter '%s': a filter with the same title exists", fe.Title)
+			continue
+		}
+
+		if fe.ExpiresAt != nil {
+			expiresIn := int64(time.Until(*fe.ExpiresAt).Seconds())
+			if expiresIn <= 0 {
+				errPrint("Skipping filter '%s': expired", fe.Title)
+				continue
+			}
+			params.ExpiresIn = &expiresIn
+		} else if current != nil {
+			// The imported filter never expires
+			var noExpiration int64
+			params.ExpiresIn = &noExpiration
+		}
+
+		// Only add missing keywords
+		currentKeywords := make(map[string]bool)
+		if current != nil {
+			for _, k := range current.Keywords {
+				currentKeywords[k.Keyword] = true
+			}
+		}
+		for _, k := range fe.Keywords {
+			if currentKeywords[k.Keyword] {
+				continue
+			}
+			params.Keywords = append(params.Keywords, mastodon.FilterKeywordParams{
+				Keyword:   k.Keyword,
+				WholeWord: k.WholeWord,
+			})
+		}
+
+		var f *mastodon.Filter
+		if current != nil {
+			if verbose {
+				errPrint("Updating filter '%s' (ID %s)", fe.Title, current.ID)
+			}
+			f, err = gExtClient.UpdateFilter(current.ID, params)
+		} else {
+			params.Title = &fe.Title
+			if verbose {
+				errPrint("Creating filter '%s'", fe.Title)
+			}
+			f, err = gExtClient.CreateFilter(params)
+		}
+		if err != nil {
+			errPrint("Cannot import filter '%s': %s", fe.Title, err)
+			failures++
+			continue
+		}
+		results = append(results, *f)
+	}
+
+	if len(results) > 0 {
+		p, err := getPrinter()
+		if err != nil {
+			errPrint("Error: %v", err)
+			os.Exit(1)
+		}
+		if err := p.printObj(results); err != nil {
+			return err
+		}
+	}
+
+	if failures > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// splitFilterContexts checks and returns the list of filter contexts
+func splitFilterContexts(contexts string) ([]string, error) {
+	var ctxList []string
+	if contexts == "" {
+		return nil, errors.New("empty filter context")
+	}
+	for _, c := range strings.Split(contexts, ",") {
+		switch c {
+		case "notification":
+			c = "notifications"
+		case "threads", "conversation", "conversations":
+			c = "thread"
+		case "accounts", "profile", "profiles":
+			c = "account"
+		}
+		valid := false
+		for _, fc := range mastodon.FilterContexts {
+			if c == fc {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, errors.Errorf("unknown filter context: '%s'", c)
+		}
+		ctxList = append(ctxList, c)
+	}
+	return ctxList, nil
+}
+
+func checkFilterAction(action string) error {
+	switch action {
+	case "warn", "hide":
+		return nil
+	}
+	return errors.Errorf("invalid filter action '%s' (should be warn or hide)", action)
+}
+
+// filterKeywordsToRemove returns the keyword parameters needed to remove the
+// keywords from the filter
+func filterKeywordsToRemove(filter *mastodon.Filter, keywords []string) ([]mastodon.FilterKeywordParams, error) {
+	var kp []mastodon.FilterKeywordParams
+	for _, rk := range keywords {
+		var id madon.ActivityID
+		for _, k := range filter.Keywords {
+			if k.Keyword == rk {
+				id = k.ID
+				break
+			}
+		}
+		if id == "" {
+			return nil, errors.Errorf("keyword '%s' not found in filter", rk)
+		}
+		kp = append(kp, mastodon.FilterKeywordParams{ID: id, Destroy: true})
+	}
+	return kp, nil
+}
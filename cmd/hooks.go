@@ -0,0 +1,187 @@
+// Copyright © 2026 Mikael Berthe <mikael@lilotux.net>
+//
+// Licensed under the MIT license.
+// Please see the LICENSE file is this directory.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/McKael/madon/v3"
+	"github.com/McKael/madonctl/v3/mastodon"
+)
+
+// Maximum delay between two attempts with the "retry" failure policy
+const hookRetryMaxDelay = time.Minute
+
+// hookJob is an external command execution request
+type hookJob struct {
+	command string
+	input   []byte   // Standard input
+	env     []string // Additional environment variables
+}
+
+// hookRunner runs the stream event hooks with a bounded worker pool
+type hookRunner struct {
+	timeout time.Duration // Per-command timeout (0 for none)
+	policy  string        // Failure policy: ignore, retry or abort
+	retries int           // Number of retries for the "retry" policy
+
+	jobs    chan hookJob
+	wg      sync.WaitGroup
+	failure chan error // Receives the first failure with the "abort" policy
+}
+
+// newHookRunner starts a hook runner with the given number of workers
+func newHookRunner(workers int, timeout time.Duration, policy string, retries int) (*hookRunner, error) {
+	switch policy {
+	case "ignore", "retry", "abort":
+	default:
+		return nil, errors.Errorf("invalid hook failure policy '%s'", policy)
+	}
+	if workers < 1 {
+		return nil, errors.New("the number of hook workers must be positive")
+	}
+
+	hr := &hookRunner{
+		timeout: timeout,
+		policy:  policy,
+		retries: retries,
+		jobs:    make(chan hookJob, workers),
+		failure: make(chan error, 1),
+	}
+	for i := 0; i < workers; i++ {
+		hr.wg.Add(1)
+		go hr.worker()
+	}
+	return hr, nil
+}
+
+// submit queues a command execution; it blocks if all the workers are busy
+// and the queue is full.
+func (hr *hookRunner) submit(command string, input []byte, env []string) {
+	hr.jobs <- hookJob{command: command, input: input, env: env}
+}
+
+// wait waits for the queued commands to terminate
+// It returns the failure reported with the "abort" policy, if any.
+func (hr *hookRunner) wait() error {
+	close(hr.jobs)
+	hr.wg.Wait()
+	select {
+	case err := <-hr.failure:
+		return err
+	default:
+		return nil
+	}
+}
+
+func (hr *hookRunner) worker() {
+	defer hr.wg.Done()
+	for job := range hr.jobs {
+		err := hr.exec(job)
+		delay := time.Second
+		for i := 0; err != nil && hr.policy == "retry" && i < hr.retries; i++ {
+			if verbose {
+				errPrint("Hook failed (%s), retrying in %v", err.Error(), delay)
+			}
+			time.Sleep(delay)
+			if delay *= 2; delay > hookRetryMaxDelay {
+				delay = hookRetryMaxDelay
+			}
+			err = hr.exec(job)
+		}
+		if err == nil {
+			continue
+		}
+		if hr.policy != "abort" {
+			errPrint("Error: %s", err.Error())
+			continue
+		}
+		select {
+		case hr.failure <- err: // The caller will report the error
+		default: // A failure has already been reported
+			errPrint("Error: %s", err.Error())
+		}
+	}
+}
+
+// exec runs a command with a shell
+func (hr *hookRunner) exec(job hookJob) error {
+	ctx := context.Background()
+	if hr.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, hr.timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", job.command)
+	cmd.Stdin = bytes.NewReader(job.input)
+	cmd.Env = append(os.Environ(), job.env...)
+	cmd.WaitDelay = time.Second // Do not wait for orphan subprocesses
+	out, err := cmd.CombinedOutput()
+	if len(out) > 0 {
+		errPrint("Command output: %s", string(out))
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return errors.Errorf("hook command timed out after %v", hr.timeout)
+	}
+	if err != nil {
+		return errors.Wrap(err, "hook command failed")
+	}
+	return nil
+}
+
+// hookEnv returns the environment variables describing a stream event
+func hookEnv(ev mastodon.StreamEvent, stream string) []string {
+	env := []string{
+		"MADONCTL_EVENT=" + ev.Event,
+		"MADONCTL_STREAM=" + stream,
+	}
+
+	statusEnv := func(s *madon.Status) {
+		env = append(env, "MADONCTL_STATUS_ID="+s.ID, "MADONCTL_VISIBILITY="+s.Visibility)
+		if s.Account != nil {
+			env = append(env, "MADONCTL_ACCOUNT="+s.Account.Acct)
+		}
+	}
+
+	switch o := ev.Data.(type) {
+	case mastodon.Status:
+		statusEnv(&o.Status)
+	case madon.Notification:
+		env = append(env, "MADONCTL_NOTIFICATION_ID="+o.ID, "MADONCTL_NOTIFICATION_TYPE="+o.Type)
+		if o.Status != nil {
+			statusEnv(o.Status)
+		}
+		if o.Account != nil { // The notification account has precedence
+			env = append(env, "MADONCTL_ACCOUNT="+o.Account.Acct)
+		}
+	case string: // Deleted status ID
+		if ev.Event == "delete" {
+			env = append(env, "MADONCTL_STATUS_ID="+o)
+		}
+	}
+	return env
+}
+
+// hookInput returns the standard input for a hook command
+func hookInput(p mcResourcePrinter, obj interface{}) ([]byte, error) {
+	if id, ok := obj.(string); ok {
+		return []byte(strings.TrimSpace(id) + "\n"), nil
+	}
+	var buf bytes.Buffer
+	if err := p.PrintObj(obj, &buf, ""); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -37,6 +37,7 @@ var listsCmd = &cobra.Command{
   madonctl lists delete --list-id 3
   madonctl lists accounts --list-id 2
   madonctl lists add-accounts --list-id 2 --account-ids 123,456
+  madonctl lists add-accounts --list-id 2 --account-ids Gargron@mastodon.social
   madonctl lists remove-accounts --list-id 2 --account-ids 456
   madonctl lists show --account-id 123`,
 }
@@ -53,7 +54,7 @@ func init() {
 
 	listsCmd.PersistentFlags().StringVarP(&listsOpts.listID, "list-id", "G", "", "List ID")
 
-	listsGetSubcommand.Flags().StringVarP(&listsOpts.accountID, "account-id", "a", "", "Account ID number")
+	listsGetSubcommand.Flags().StringVarP(&listsOpts.accountID, "account-id", "a", "", "Account ID, address or URL")
 	// XXX accountUID?
 
 	listsGetAccountsSubcommand.Flags().StringVarP(&listsOpts.listID, "list-id", "G", "", "List ID")
@@ -61,10 +62,10 @@ func init() {
 	listsCreateSubcommand.Flags().StringVar(&listsOpts.title, "title", "", "List title")
 	listsUpdateSubcommand.Flags().StringVar(&listsOpts.title, "title", "", "List title")
 
-	listsAddAccountsSubcommand.Flags().StringVar(&listsOpts.accountIDs, "account-ids", "", "Comma-separated list of account IDs")
-	listsAddAccountsSubcommand.Flags().StringVarP(&listsOpts.accountID, "account-id", "a", "", "Account ID number")
-	listsRemoveAccountsSubcommand.Flags().StringVar(&listsOpts.accountIDs, "account-ids", "", "Comma-separated list of account IDs")
-	listsRemoveAccountsSubcommand.Flags().StringVarP(&listsOpts.accountID, "account-id", "a", "", "Account ID number")
+	listsAddAccountsSubcommand.Flags().StringVar(&listsOpts.accountIDs, "account-ids", "", "Comma-separated list of account IDs, addresses or URLs")
+	listsAddAccountsSubcommand.Flags().StringVarP(&listsOpts.accountID, "account-id", "a", "", "Account ID, address or URL")
+	listsRemoveAccountsSubcommand.Flags().StringVar(&listsOpts.accountIDs, "account-ids", "", "Comma-separated list of account IDs, addresses or URLs")
+	listsRemoveAccountsSubcommand.Flags().StringVarP(&listsOpts.accountID, "account-id", "a", "", "Account ID, address or URL")
 }
 
 var listsSubcommands = []*cobra.Command{
@@ -132,6 +133,16 @@ func listsGetRunE(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if opt.accountID != "" {
+		// The account can be given as an address or an URL
+		id, err := accountLookupUser(opt.accountID)
+		if err != nil {
+			errPrint("Cannot find user '%s': %v", opt.accountID, err)
+			os.Exit(1)
+		}
+		opt.accountID = id
+	}
+
 	// Set up LimitParams
 	var limOpts *madon.LimitParams
 	if opt.all || opt.limit > 0 {
@@ -304,17 +315,10 @@ func listsAddRemoveAccountsRunE(cmd *cobra.Command, args []string) error {
 		return errors.New("missing list ID")
 	}
 
-	var ids []madon.ActivityID
-	var err error
-	ids, err = splitIDs(opt.accountIDs)
-	if err != nil {
-		return errors.New("cannot parse account IDs")
-	}
-
 	if opt.accountID != "" { // Allow --account-id
-		ids = []madon.ActivityID{opt.accountID}
+		opt.accountIDs = opt.accountID
 	}
-	if len(ids) < 1 {
+	if len(opt.accountIDs) == 0 {
 		return errors.New("missing account IDs")
 	}
 
@@ -323,6 +327,16 @@ func listsAddRemoveAccountsRunE(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// The accounts can be given as IDs, addresses or URLs
+	ids, err := resolveAccountIDs(opt.accountIDs)
+	if err != nil {
+		errPrint("Error: %s", err.Error())
+		os.Exit(1)
+	}
+	if len(ids) < 1 {
+		return errors.New("missing account IDs")
+	}
+
 	switch cmd.Name() {
 	case "add-account", "add-accounts":
 		err = gClient.AddListAccounts(opt.listID, ids)
@@ -0,0 +1,209 @@
+// Copyright © 2026 Mikael Berthe <mikael@lilotux.net>
+//
+// Licensed under the MIT license.
+// Please see the LICENSE file is this directory.
+
+package cmd
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+
+	"github.com/McKael/madon/v3"
+	"github.com/McKael/madonctl/v3/mastodon"
+	"github.com/McKael/madonctl/v3/printer/html2text"
+)
+
+// localFilters contains the client-side filters from the configuration
+// file ("local_filters" section)
+type localFilters struct {
+	content  []*regexp.Regexp // Status text (and content warning)
+	accounts []*regexp.Regexp // Account address (user@domain)
+	domains  []*regexp.Regexp // Account domain
+	hashtags []*regexp.Regexp // Status hashtag (without '#')
+	apps     []*regexp.Regexp // Name of the application used to post
+
+	dropped int // Number of items dropped
+}
+
+// loadLocalFilters compiles the local filters from the configuration.
+// It returns nil if there are no local filters or if they have been
+// disabled with --no-local-filters.
+func loadLocalFilters() (*localFilters, error) {
+	if noLocalFilters || !viper.IsSet("local_filters") {
+		return nil, nil
+	}
+
+	lf := new(localFilters)
+	for _, f := range []struct {
+		key  string
+		list *[]*regexp.Regexp
+	}{
+		{"content", &lf.content},
+		{"accounts", &lf.accounts},
+		{"domains", &lf.domains},
+		{"hashtags", &lf.hashtags},
+		{"apps", &lf.apps},
+	} {
+		for _, expr := range viper.GetStringSlice("local_filters." + f.key) {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid local filter (%s)", f.key)
+			}
+			*f.list = append(*f.list, re)
+		}
+	}
+
+	if len(lf.content)+len(lf.accounts)+len(lf.domains)+len(lf.hashtags)+len(lf.apps) == 0 {
+		return nil, nil
+	}
+	return lf, nil
+}
+
+func matchAny(rl []*regexp.Regexp, s string) bool {
+	for _, re := range rl {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// accountAddress returns the user@domain address and the domain of an
+// account.  The domain of local accounts is taken from the account URL.
+func accountAddress(a *madon.Account) (address, domain string) {
+	if i := strings.IndexByte(a.Acct, '@'); i >= 0 {
+		return a.Acct, a.Acct[i+1:]
+	}
+	if u, err := url.Parse(a.URL); err == nil && u.Host != "" {
+		return a.Acct + "@" + u.Host, u.Host
+	}
+	return a.Acct, ""
+}
+
+// matchAccount returns true if the account matches an account or domain
+// filter
+func (lf *localFilters) matchAccount(a *madon.Account) bool {
+	if lf == nil || a == nil {
+		return false
+	}
+	address, domain := accountAddress(a)
+	return matchAny(lf.accounts, address) || matchAny(lf.domains, domain)
+}
+
+// matchStatus returns true if the status (or the status it reblogs) matches
+// one of the filters
+func (lf *localFilters) matchStatus(s *madon.Status) bool {
+	if lf == nil || s == nil {
+		return false
+	}
+	if lf.matchAccount(s.Account) || lf.matchStatus(s.Reblog) {
+		return true
+	}
+	for _, t := range s.Tags {
+		if matchAny(lf.hashtags, t.Name) {
+			return true
+		}
+	}
+	if s.Application != nil && matchAny(lf.apps, s.Application.Name) {
+		return true
+	}
+	if len(lf.content) > 0 {
+		if matchAny(lf.content, s.SpoilerText) {
+			return true
+		}
+		text, err := html2text.Textify(s.Content)
+		if err != nil {
+			text = s.Content
+		}
+		if matchAny(lf.content, text) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchNotification returns true if the notification account or the
+// related status match one of the filters
+func (lf *localFilters) matchNotification(n *madon.Notification) bool {
+	if lf == nil {
+		return false
+	}
+	return lf.matchAccount(n.Account) || lf.matchStatus(n.Status)
+}
+
+// filterStatuses returns the statuses that do not match the filters
+func (lf *localFilters) filterStatuses(sl []mastodon.Status) []mastodon.Status {
+	if lf == nil {
+		return sl
+	}
+	var kept []mastodon.Status
+	for _, s := range sl {
+		if lf.matchStatus(s.MadonStatus()) {
+			lf.dropped++
+			continue
+		}
+		kept = append(kept, s)
+	}
+	return kept
+}
+
+// filterNotifications returns the notifications that do not match the
+// filters
+func (lf *localFilters) filterNotifications(nl []madon.Notification) []madon.Notification {
+	if lf == nil {
+		return nl
+	}
+	var kept []madon.Notification
+	for _, n := range nl {
+		if lf.matchNotification(&n) {
+			lf.dropped++
+			continue
+		}
+		kept = append(kept, n)
+	}
+	return kept
+}
+
+// filterResults removes the search results that match the filters
+func (lf *localFilters) filterResults(r *madon.Results) {
+	if lf == nil || r == nil {
+		return
+	}
+	var accounts []madon.Account
+	for _, a := range r.Accounts {
+		if lf.matchAccount(&a) {
+			lf.dropped++
+			continue
+		}
+		accounts = append(accounts, a)
+	}
+	var statuses []madon.Status
+	for _, s := range r.Statuses {
+		if lf.matchStatus(&s) {
+			lf.dropped++
+			continue
+		}
+		statuses = append(statuses, s)
+	}
+	var hashtags []madon.Tag
+	for _, t := range r.Hashtags {
+		if matchAny(lf.hashtags, t.Name) {
+			lf.dropped++
+			continue
+		}
+		hashtags = append(hashtags, t)
+	}
+	r.Accounts, r.Statuses, r.Hashtags = accounts, statuses, hashtags
+}
+
+// report displays the number of dropped items in verbose mode
+func (lf *localFilters) report() {
+	if lf != nil && verbose {
+		errPrint("Local filters: %d item(s) dropped", lf.dropped)
+	}
+}
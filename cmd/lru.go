@@ -0,0 +1,41 @@
+// Copyright © 2026 Mikael Berthe <mikael@lilotux.net>
+//
+// Licensed under the MIT license.
+// Please see the LICENSE file is this directory.
+
+package cmd
+
+import (
+	"container/list"
+)
+
+// lruSet is a set of strings with a bounded size; when the set is full,
+// the least recently used key is evicted.
+type lruSet struct {
+	size  int
+	order *list.List // Most recently used keys first
+	keys  map[string]*list.Element
+}
+
+func newLRUSet(size int) *lruSet {
+	return &lruSet{
+		size:  size,
+		order: list.New(),
+		keys:  make(map[string]*list.Element),
+	}
+}
+
+// seen adds the key to the set and returns true if it was already present
+func (s *lruSet) seen(key string) bool {
+	if e, ok := s.keys[key]; ok {
+		s.order.MoveToFront(e)
+		return true
+	}
+	s.keys[key] = s.order.PushFront(key)
+	if s.order.Len() > s.size {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.keys, oldest.Value.(string))
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRUSet(t *testing.T) {
+	s := newLRUSet(3)
+	steps := []struct {
+		key  string
+		seen bool
+	}{
+		{"a", false},
+		{"b", false},
+		{"c", false},
+		{"a", true},  // a is now the most recently used key
+		{"d", false}, // b is evicted
+		{"b", false}, // c is evicted
+		{"a", true},
+		{"d", true},
+		{"c", false},
+	}
+	for i, step := range steps {
+		assert.Equal(t, step.seen, s.seen(step.key), "step %d (%s)", i, step.key)
+		assert.True(t, len(s.keys) <= 3)
+		assert.Equal(t, len(s.keys), s.order.Len())
+	}
+}
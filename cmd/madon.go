@@ -6,11 +6,14 @@
 package cmd
 
 import (
+	"os"
 	"strings"
 
 	"github.com/McKael/madon/v3"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
+
+	"github.com/McKael/madonctl/v3/mastodon"
 )
 
 var scopes = []string{"read", "write", "follow"}
@@ -21,6 +24,9 @@ func madonInit(signIn bool) error {
 			return err
 		}
 	}
+	if gExtClient == nil {
+		gExtClient = mastodon.NewClient(gClient)
+	}
 	if signIn {
 		return madonLogin()
 	}
@@ -102,6 +108,46 @@ func madonLogin() error {
 	return errors.Wrap(err, "login failed")
 }
 
+// madonProfileClient returns a new client signed in with the settings
+// (instance, application and user credentials) of another configuration
+// file, e.g. to use a second account.
+func madonProfileClient(configFile string) (*madon.Client, error) {
+	v := viper.New()
+	v.SetConfigFile(os.ExpandEnv(configFile))
+	if err := v.ReadInConfig(); err != nil {
+		return nil, errors.Wrap(err, "cannot read profile configuration")
+	}
+
+	instance := v.GetString("instance")
+	if instance == "" {
+		return nil, errors.Errorf("no instance provided in '%s'", configFile)
+	}
+
+	var c *madon.Client
+	var err error
+	if v.GetString("app_id") != "" && v.GetString("app_secret") != "" {
+		c, err = madon.RestoreApp(AppName, instance, v.GetString("app_id"), v.GetString("app_secret"), nil)
+	} else {
+		c, err = madon.NewApp(AppName, AppWebsite, scopes, madon.NoRedirect, instance)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot initialize client for '%s'", instance)
+	}
+
+	if t := v.GetString("token"); t != "" {
+		err = c.SetUserToken(t, v.GetString("login"), v.GetString("password"), []string{})
+	} else {
+		err = c.LoginBasic(v.GetString("login"), v.GetString("password"), scopes)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "login failed for '%s'", instance)
+	}
+	if verbose {
+		errPrint("Profile '%s': instance '%s'", configFile, instance)
+	}
+	return c, nil
+}
+
 // splitIDs splits a list of IDs into an int64 array
 func splitIDs(ids string) (list []madon.ActivityID, err error) {
 	if ids == "" {
@@ -91,6 +91,11 @@ func notificationRunE(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	lf, err := loadLocalFilters()
+	if err != nil {
+		return err
+	}
+
 	var xTypes []string
 	if xt, err := splitNotificationTypes(opt.excludeTypes); err == nil {
 		xTypes = xt
@@ -99,7 +104,6 @@ func notificationRunE(cmd *cobra.Command, args []string) error {
 	}
 
 	var obj interface{}
-	var err error
 
 	if opt.list {
 		var notifications []madon.Notification
@@ -119,6 +123,10 @@ func notificationRunE(cmd *cobra.Command, args []string) error {
 			notifications = newNotifications
 		}
 
+		// Apply local filters
+		notifications = lf.filterNotifications(notifications)
+		lf.report()
+
 		if accountsOpts.keep > 0 && len(notifications) > int(accountsOpts.keep) {
 			notifications = notifications[:accountsOpts.keep]
 		}
@@ -0,0 +1,362 @@
+// Copyright © 2026 Mikael Berthe <mikael@lilotux.net>
+//
+// Licensed under the MIT license.
+// Please see the LICENSE file is this directory.
+
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+
+	"github.com/McKael/madon/v3"
+	"github.com/McKael/madonctl/v3/mastodon"
+)
+
+// accountIDCache is a local cache of the account IDs, indexed by instance
+// host and account address
+// The changes are only written to the cache file by save, so that the bulk
+// operations do not rewrite the file for every account.
+type accountIDCache struct {
+	file    string // Empty if the cache is not persistent
+	entries map[string]map[string]madon.ActivityID
+	dirty   bool // True if the entries have been modified since last save
+}
+
+var gAccountCache *accountIDCache
+
+// loadAccountCache returns the account ID cache, reading the cache file
+// the first time
+// The cache file can be disabled with the account_cache setting.
+func loadAccountCache() *accountIDCache {
+	if gAccountCache != nil {
+		return gAccountCache
+	}
+	gAccountCache = &accountIDCache{entries: make(map[string]map[string]madon.ActivityID)}
+
+	if viper.IsSet("account_cache") && !viper.GetBool("account_cache") {
+		return gAccountCache
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return gAccountCache
+	}
+	gAccountCache.file = filepath.Join(cacheDir, AppName, "accounts.json")
+
+	data, err := ioutil.ReadFile(gAccountCache.file)
+	if err != nil {
+		return gAccountCache
+	}
+	if err := json.Unmarshal(data, &gAccountCache.entries); err != nil {
+		errPrint("Warning: cannot read the account cache: %v", err)
+		gAccountCache.entries = make(map[string]map[string]madon.ActivityID)
+	}
+	return gAccountCache
+}
+
+func (ac *accountIDCache) get(host, acct string) (madon.ActivityID, bool) {
+	id, ok := ac.entries[host][acct]
+	return id, ok
+}
+
+func (ac *accountIDCache) set(host, acct string, id madon.ActivityID) {
+	if ac.entries[host] == nil {
+		ac.entries[host] = make(map[string]madon.ActivityID)
+	}
+	if ac.entries[host][acct] == id {
+		return
+	}
+	ac.entries[host][acct] = id
+	ac.dirty = true
+}
+
+func (ac *accountIDCache) remove(host, acct string) {
+	if _, ok := ac.entries[host][acct]; !ok {
+		return
+	}
+	delete(ac.entries[host], acct)
+	ac.dirty = true
+}
+
+// save writes the cache file if the cache has been modified
+// Errors are not fatal, the cache is only an optimization.
+func (ac *accountIDCache) save() {
+	if ac.file == "" || !ac.dirty {
+		return
+	}
+	data, err := json.Marshal(ac.entries)
+	if err != nil {
+		return
+	}
+	dir := filepath.Dir(ac.file)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return
+	}
+
+	// Write a unique temporary file first, so that concurrent processes
+	// do not overwrite each other's partial writes.
+	tmp, err := ioutil.TempFile(dir, filepath.Base(ac.file)+".*.tmp")
+	if err != nil {
+		if verbose {
+			errPrint("Warning: cannot write the account cache: %v", err)
+		}
+		return
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), ac.file)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		if verbose {
+			errPrint("Warning: cannot write the account cache: %v", err)
+		}
+		return
+	}
+	ac.dirty = false
+}
+
+// accountResolver finds accounts from their IDs, addresses or URLs
+//
+// The addresses (user@domain) are resolved using the lookup API, then a
+// WebFinger query to the remote domain, then the search API (with remote
+// resolution).  The account IDs are cached locally.
+type accountResolver struct {
+	client *madon.Client
+	ext    *mastodon.Client
+	host   string // Instance host (cache key)
+	cache  *accountIDCache
+}
+
+// newAccountResolver returns an account resolver using the client c
+func newAccountResolver(c *madon.Client) *accountResolver {
+	r := &accountResolver{client: c, cache: loadAccountCache()}
+	if c == gClient && gExtClient != nil {
+		r.ext = gExtClient
+	} else {
+		r.ext = mastodon.NewClient(c)
+	}
+	if u, err := url.Parse(c.InstanceURL); err == nil {
+		r.host = strings.ToLower(u.Host)
+	}
+	return r
+}
+
+// isAccountID returns true if the string is a numeric account ID
+func isAccountID(s string) bool {
+	_, err := strconv.ParseInt(s, 10, 64)
+	return err == nil
+}
+
+// isHTTPURL returns true if the string is an HTTP(S) URL
+func isHTTPURL(s string) bool {
+	return strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://")
+}
+
+// normalize returns the address of an account as used by the instance:
+// the user name for the local accounts, user@domain for the remote accounts
+func (r *accountResolver) normalize(address string) string {
+	a := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(address), "@"))
+	if i := strings.LastIndex(a, "@"); i > 0 && a[i+1:] == r.host {
+		a = a[:i]
+	}
+	return a
+}
+
+// resolveID returns the ID of the account identified by user (an account
+// ID, an address or an URL)
+// The cached IDs are returned without any API call.
+func (r *accountResolver) resolveID(user string) (madon.ActivityID, error) {
+	user = strings.TrimSpace(user)
+	if isAccountID(user) {
+		return user, nil
+	}
+	if !isHTTPURL(user) {
+		if id, ok := r.cache.get(r.host, r.normalize(user)); ok {
+			return id, nil
+		}
+	}
+	a, err := r.resolve(user)
+	if err != nil {
+		return "", err
+	}
+	return a.ID, nil
+}
+
+// resolve returns the account identified by user (an account ID, an
+// address or an URL)
+func (r *accountResolver) resolve(user string) (*madon.Account, error) {
+	user = strings.TrimSpace(user)
+	switch {
+	case user == "" || user == "@":
+		return nil, errors.New("empty account identifier")
+	case isAccountID(user):
+		return r.client.GetAccount(user)
+	case isHTTPURL(user):
+		a, err := r.searchURL(user)
+		if err != nil {
+			return nil, err
+		}
+		r.cache.set(r.host, r.normalize(a.Acct), a.ID)
+		return a, nil
+	}
+
+	acct := r.normalize(user)
+	if id, ok := r.cache.get(r.host, acct); ok {
+		if a, err := r.client.GetAccount(id); err == nil {
+			return a, nil
+		}
+		r.cache.remove(r.host, acct) // Stale entry
+	}
+
+	a, err := r.lookup(acct)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.set(r.host, acct, a.ID)
+	return a, nil
+}
+
+// lookup finds an account from its address
+func (r *accountResolver) lookup(acct string) (*madon.Account, error) {
+	// The lookup API is fast and exact, but only works for the accounts
+	// already known by the instance.
+	a, err := r.ext.LookupAccount(acct)
+	if err == nil {
+		if verbose {
+			errPrint("Account '%s' found with the lookup API (ID %s)", acct, a.ID)
+		}
+		return a, nil
+	}
+	if isRateLimited(err) {
+		return nil, err // The fallbacks would be rate-limited too
+	}
+
+	// Ask the remote server for the account URL, and let the instance
+	// resolve this URL.
+	if strings.Contains(acct, "@") {
+		wf, err := mastodon.WebFinger(acct)
+		if err == nil && wf.ActorURL() != "" {
+			if a, err := r.searchURL(wf.ActorURL()); err == nil {
+				if verbose {
+					errPrint("Account '%s' found with WebFinger (ID %s)", acct, a.ID)
+				}
+				return a, nil
+			}
+		} else if verbose && err != nil {
+			errPrint("WebFinger: %v", err)
+		}
+	}
+
+	a, err = r.search(acct)
+	if err != nil {
+		return nil, err
+	}
+	if verbose {
+		errPrint("Account '%s' found with the search API (ID %s)", acct, a.ID)
+	}
+	return a, nil
+}
+
+// searchURL finds an account from its URL, using the search API (the
+// remote accounts are resolved by the server)
+func (r *accountResolver) searchURL(u string) (*madon.Account, error) {
+	res, err := r.ext.Search(u, true)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil || len(res.Accounts) == 0 {
+		return nil, errors.New("account not found")
+	}
+	if len(res.Accounts) > 1 {
+		return nil, errors.New("several accounts found")
+	}
+	return &res.Accounts[0], nil
+}
+
+// search finds an account from its address, using the search API (the
+// remote accounts are resolved by the server)
+func (r *accountResolver) search(acct string) (*madon.Account, error) {
+	res, err := r.ext.Search(acct, true)
+	if err != nil {
+		return nil, err
+	}
+	if res != nil {
+		for i, a := range res.Accounts {
+			addr, _ := accountAddress(&a)
+			if strings.EqualFold(a.Acct, acct) || strings.EqualFold(addr, acct) ||
+				r.normalize(a.Acct) == acct {
+				return &res.Accounts[i], nil
+			}
+		}
+	}
+	return nil, errors.New("account not found")
+}
+
+// resolveAccountIDs returns the IDs of the accounts of a comma-separated
+// list of account IDs, addresses or URLs
+func resolveAccountIDs(list string) ([]madon.ActivityID, error) {
+	ids, err := splitIDs(list)
+	if err != nil {
+		return nil, err
+	}
+	r := newAccountResolver(gClient)
+	defer r.cache.save()
+	for i, user := range ids {
+		if ids[i], err = r.resolveID(user); err != nil {
+			return nil, errors.Wrapf(err, "cannot find account '%s'", user)
+		}
+	}
+	return ids, nil
+}
+
+// resolveStatusID returns the ID of the status identified by s (a status ID
+// or an URL)
+// The URLs are resolved using the search API, so that the remote statuses
+// can be fetched by the instance.
+func resolveStatusID(s string) (madon.ActivityID, error) {
+	s = strings.TrimSpace(s)
+	if !isHTTPURL(s) {
+		return s, nil
+	}
+	res, err := gClient.Search(s, true)
+	if err != nil {
+		return "", err
+	}
+	if res == nil || len(res.Statuses) == 0 {
+		return "", errors.Errorf("cannot find status '%s'", s)
+	}
+	if len(res.Statuses) > 1 {
+		return "", errors.Errorf("several statuses match '%s'", s)
+	}
+	if verbose {
+		errPrint("Status '%s' is status ID %s", s, res.Statuses[0].ID)
+	}
+	return res.Statuses[0].ID, nil
+}
+
+// resolveStatusIDs returns the IDs of the statuses of a comma-separated list
+// of status IDs or URLs
+func resolveStatusIDs(list string) ([]madon.ActivityID, error) {
+	ids, err := splitIDs(list)
+	if err != nil {
+		return nil, err
+	}
+	for i := range ids {
+		if ids[i], err = resolveStatusID(ids[i]); err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountResolverNormalize(t *testing.T) {
+	r := &accountResolver{host: "mastodon.example"}
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"alice", "alice"},
+		{"@alice", "alice"},
+		{" Alice ", "alice"},
+		{"alice@mastodon.example", "alice"},
+		{"@Alice@Mastodon.Example", "alice"},
+		{"alice@remote.example", "alice@remote.example"},
+		{"@Bob@Remote.Example", "bob@remote.example"},
+		{"alice@mastodon.example.org", "alice@mastodon.example.org"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, r.normalize(tt.input), tt.input)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/McKael/madon/v3"
+	"github.com/McKael/madonctl/v3/mastodon"
 )
 
 // AppName is the CLI application name
@@ -26,6 +27,9 @@ const defaultConfigFile = "$HOME/.config/" + AppName + "/" + AppName + ".yaml"
 // Madon API client
 var gClient *madon.Client
 
+// Extended API client (for the API calls the madon library does not provide)
+var gExtClient *mastodon.Client
+
 // Options
 var cfgFile string
 var safeMode bool
@@ -35,6 +39,8 @@ var verbose bool
 var outputFormat string
 var outputTemplate, outputTemplateFile, outputTheme string
 var colorMode string
+var showFiltered bool
+var noLocalFilters bool
 
 // Shell completion functions
 const shellComplFunc = `
@@ -146,6 +152,10 @@ func init() {
 		"Theme name (for output=theme)")
 	RootCmd.PersistentFlags().StringVar(&colorMode, "color", "",
 		"Color mode (auto|on|off; for output=template)")
+	RootCmd.PersistentFlags().BoolVar(&showFiltered, "show-filtered", false,
+		"Display statuses hidden by server-side filters")
+	RootCmd.PersistentFlags().BoolVar(&noLocalFilters, "no-local-filters", false,
+		"Do not apply the local filters from the configuration")
 
 	// Configuration file bindings
 	viper.BindPFlag("verbose", RootCmd.PersistentFlags().Lookup("verbose"))
@@ -154,6 +164,7 @@ func init() {
 	viper.BindPFlag("password", RootCmd.PersistentFlags().Lookup("password"))
 	viper.BindPFlag("token", RootCmd.PersistentFlags().Lookup("token"))
 	viper.BindPFlag("color", RootCmd.PersistentFlags().Lookup("color"))
+	viper.BindPFlag("show_filtered", RootCmd.PersistentFlags().Lookup("show-filtered"))
 
 	// Flag completion
 	annotationOutput := make(map[string][]string)
@@ -44,12 +44,20 @@ func searchRunE(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	lf, err := loadLocalFilters()
+	if err != nil {
+		return err
+	}
+
 	results, err := gClient.Search(strings.Join(args, " "), opt.resolve)
 	if err != nil {
 		errPrint("Error: %s", err.Error())
 		os.Exit(1)
 	}
 
+	lf.filterResults(results)
+	lf.report()
+
 	p, err := getPrinter()
 	if err != nil {
 		errPrint("Error: %s", err.Error())
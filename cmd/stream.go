@@ -6,28 +6,75 @@
 package cmd
 
 import (
+	"encoding/json"
 	"io"
+	"math/rand"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 
 	"github.com/McKael/madon/v3"
+	"github.com/McKael/madonctl/v3/mastodon"
 )
 
 var streamOpts struct {
 	command           string
+	record            string
+	speed             string
+	webhook           string
+	webhookHeaders    []string
+	webhookSecret     string
+	webhookSigHeader  string
+	webhookQueue      string
+	webhookQueueSize  uint
+	onUpdate          string
+	onNotification    string
+	onDelete          string
+	hookWorkers       uint
+	hookTimeout       time.Duration
+	hookFailure       string
+	hookRetries       uint
 	notificationsOnly bool
 	notificationTypes string
+	events            string
+	eventsJSON        bool
+	noReconnect       bool
+	noDedup           bool
+	idleTimeout       time.Duration
+	statsInterval     time.Duration
+	listen            string
+	connect           string
+	serverToken       string
+	stats             bool
+	statsWindow       time.Duration
+	statsTop          uint
+	summaryInterval   time.Duration
+	alertRules        string
 }
 
-// Maximum number of websockets (1 hashtag <=> 1 ws)
-const maximumHashtagStreamWS = 4
+// Reconnection delays (the delay is doubled after each failed attempt)
+const (
+	streamReconnectMinDelay = time.Second
+	streamReconnectMaxDelay = 5 * time.Minute
+)
+
+// Number of recent events remembered for deduplication
+const streamDedupSize = 2000
+
+// Backfill limits after a reconnection
+const (
+	streamBackfillPageSize = 40
+	streamBackfillMaxPages = 10
+)
 
 // streamCmd represents the stream command
 var streamCmd = &cobra.Command{
-	Use:   "stream [user|local|public|direct|!LIST|:HASHTAG]",
+	Use:   "stream [user|local|public|direct|!LIST|:HASHTAG...]",
 	Short: "Listen to an event stream",
 	Long: `Listen to an event stream
 
@@ -35,7 +82,11 @@ The stream command stays connected to the server and listen to a stream of
 events (user, local or federated).
 A list-based stream can be displayed by prefixing the list ID with a '!'.
 It can also get a hashtag-based stream if the keyword is prefixed with
-':' or '#'.`,
+':' or '#'.
+
+Several streams can be given; they are multiplexed on a single connection.
+A status received from several streams (e.g. a status with two of the
+hashtags) is only displayed once, unless --no-dedup is used.`,
 	Example: `  madonctl stream           # User timeline stream
   madonctl stream local     # Local timeline stream
   madonctl stream public    # Public timeline stream
@@ -45,72 +96,179 @@ It can also get a hashtag-based stream if the keyword is prefixed with
   madonctl stream #madonctl
   madonctl stream --notifications-only
   madonctl stream --notifications-only --notification-types mentions,follows
+  madonctl stream user '!42' :golang :rust local
+  madonctl stream --events update,status.update
+  madonctl stream --events-json | jq .event
+  madonctl stream --idle-timeout 2m --stats-interval 10m
+  madonctl stream public --stats --stats-window 15m --stats-top 5
+  madonctl stream public --alert-rules rules.yaml
+  madonctl stream --webhook https://bot.example.com/hook --webhook-secret s3cr3t
+  madonctl stream --on-notification 'notify-send "$MADONCTL_ACCOUNT" "$MADONCTL_NOTIFICATION_TYPE"'
 
-Several (up to 4) hashtags can be given.
-Note: madonctl will use 1 websocket per hashtag stream.
+Several hashtags can be given, separated by commas:
   madonctl stream #madonctl,#mastodon,#golang
   madonctl stream :madonctl,mastodon,api
+
+When the connection is lost, madonctl reconnects automatically and fetches
+the statuses and notifications that have been missed, except for the direct
+stream (use --no-reconnect to stop streaming instead).
+With --idle-timeout, the client sends heartbeat pings to the server and
+the connection is restarted if nothing (event, ping or pong) has been
+received during the given delay.
+With --stats-interval, a status report (number of events per type,
+number of reconnections, age of the last event and heartbeat) is
+periodically displayed on the standard error output, or written to the
+standard output as a "stats" event with --events-json.
+
+With --stats, the statuses are not displayed; they are aggregated in a
+sliding window (see --stats-window) and a summary (statuses per minute, top
+hashtags, domains, languages and posters) is displayed periodically (see
+--summary-interval).  The summary is a text table with the plain and theme
+output formats, and a JSON object (a "summary" event with --events-json)
+otherwise.
+
+With --alert-rules, the statuses are checked against the rules of a YAML
+file and only the alerts are displayed.  Each rule can have regular
+expressions for the status text (and content warning), the account address,
+the account domain and the hashtags (all of them must match), a cooldown
+delay, and an action: "print" (default; the matching text is highlighted),
+"command" (a shell command run like the hooks, with MADONCTL_ALERT_RULE
+set) or "file" (the alert is appended to a file as a JSON line):
+
+  rules:
+    - name: product
+      text: "(?i)\\bmadonctl\\b"
+      cooldown: 10m
+    - name: team
+      domain: "^example\\.com$"
+      tags: "(?i)^golang$"
+      action: file
+      file: /var/log/madonctl-alerts.jsonl
+
+The --on-update, --on-notification and --on-delete hooks are shell commands
+run for each matching event, with the event object (formatted according to
+the output options) on their standard input.  The following environment
+variables are set: MADONCTL_EVENT, MADONCTL_STREAM, MADONCTL_STATUS_ID,
+MADONCTL_ACCOUNT, MADONCTL_VISIBILITY, MADONCTL_NOTIFICATION_ID and
+MADONCTL_NOTIFICATION_TYPE (when relevant).
+Hooks run concurrently (see --hook-workers), so their order is not
+guaranteed.
 `,
 	RunE:       streamRunE,
-	ValidArgs:  []string{"user", "public", "direct"},
+	ValidArgs:  []string{"user", "public", "local", "direct"},
 	ArgAliases: []string{"home"},
 }
 
 func init() {
 	RootCmd.AddCommand(streamCmd)
 
-	streamCmd.Flags().StringVar(&streamOpts.command, "command", "", "Execute external command")
-	streamCmd.Flags().BoolVar(&streamOpts.notificationsOnly, "notifications-only", false, "Display only notifications (user stream)")
-	streamCmd.Flags().StringVar(&streamOpts.notificationTypes, "notification-types", "", "Filter notifications (mentions, favourites, reblogs, follows)")
+	// Subcommands
+	streamCmd.AddCommand(streamReplaySubcommand)
+	streamCmd.AddCommand(streamServeSubcommand)
+
+	streamReplaySubcommand.Flags().StringVar(&streamOpts.speed, "speed", "1x", "Replay speed factor (e.g. 10x; 0 for no delay)")
+	streamServeSubcommand.Flags().StringVar(&streamOpts.listen, "listen", "", "Listen address (unix:PATH or localhost:PORT)")
+
+	streamCmd.PersistentFlags().StringVar(&streamOpts.command, "command", "", "Execute external command")
+	streamCmd.PersistentFlags().StringVar(&streamOpts.connect, "connect", "", "Read the events from a local stream server (unix:PATH or localhost:PORT)")
+	streamCmd.PersistentFlags().StringVar(&streamOpts.serverToken, "server-token", "", "Local stream server token (required for TCP addresses)")
+	streamCmd.PersistentFlags().StringVar(&streamOpts.record, "record", "", "Record the raw events to a file (JSON lines)")
+	streamCmd.PersistentFlags().StringVar(&streamOpts.webhook, "webhook", "", "Forward events to a webhook URL (JSON POST requests)")
+	streamCmd.PersistentFlags().StringArrayVar(&streamOpts.webhookHeaders, "webhook-header", nil, "Additional webhook HTTP header (\"Name: value\")")
+	streamCmd.PersistentFlags().StringVar(&streamOpts.webhookSecret, "webhook-secret", "", "Webhook HMAC-SHA256 signature key")
+	streamCmd.PersistentFlags().StringVar(&streamOpts.webhookSigHeader, "webhook-signature-header", "X-Hub-Signature-256", "Webhook signature HTTP header")
+	streamCmd.PersistentFlags().StringVar(&streamOpts.webhookQueue, "webhook-queue", "", "Webhook queue directory (default: per-URL directory in the user cache)")
+	streamCmd.PersistentFlags().UintVar(&streamOpts.webhookQueueSize, "webhook-queue-size", 1000, "Maximum number of queued webhook events")
+	streamCmd.PersistentFlags().StringVar(&streamOpts.onUpdate, "on-update", "", "Shell command to run for status updates")
+	streamCmd.PersistentFlags().StringVar(&streamOpts.onNotification, "on-notification", "", "Shell command to run for notifications")
+	streamCmd.PersistentFlags().StringVar(&streamOpts.onDelete, "on-delete", "", "Shell command to run for deletions")
+	streamCmd.PersistentFlags().UintVar(&streamOpts.hookWorkers, "hook-workers", 4, "Maximum number of concurrent hook commands")
+	streamCmd.PersistentFlags().DurationVar(&streamOpts.hookTimeout, "hook-timeout", time.Minute, "Hook command timeout (0 for none)")
+	streamCmd.PersistentFlags().StringVar(&streamOpts.hookFailure, "hook-failure", "ignore", "Hook failure policy (ignore, retry, abort)")
+	streamCmd.PersistentFlags().UintVar(&streamOpts.hookRetries, "hook-retries", 3, "Number of retries with the retry failure policy")
+	streamCmd.PersistentFlags().BoolVar(&streamOpts.notificationsOnly, "notifications-only", false, "Display only notifications (user stream)")
+	streamCmd.PersistentFlags().StringVar(&streamOpts.notificationTypes, "notification-types", "", "Filter notifications (mentions, favourites, reblogs, follows)")
+	streamCmd.PersistentFlags().StringVar(&streamOpts.events, "events", "", "Filter events (update, status.update, notification, delete, conversation, announcement, announcement.reaction, announcement.delete, filters_changed)")
+	streamCmd.PersistentFlags().BoolVar(&streamOpts.eventsJSON, "events-json", false, "Output one JSON event envelope per line (NDJSON)")
+	streamCmd.PersistentFlags().BoolVar(&streamOpts.noReconnect, "no-reconnect", false, "Do not reconnect when the connection is lost")
+	streamCmd.PersistentFlags().DurationVar(&streamOpts.idleTimeout, "idle-timeout", 0, "Restart the connection after this delay without activity (0 to disable)")
+	streamCmd.PersistentFlags().DurationVar(&streamOpts.statsInterval, "stats-interval", 0, "Interval between stream status reports (0 to disable)")
+	streamCmd.PersistentFlags().StringVar(&streamOpts.alertRules, "alert-rules", "", "Alert rule file (YAML)")
+	streamCmd.PersistentFlags().BoolVar(&streamOpts.stats, "stats", false, "Display periodic statistics instead of the statuses")
+	streamCmd.PersistentFlags().DurationVar(&streamOpts.statsWindow, "stats-window", 10*time.Minute, "Statistics sliding window")
+	streamCmd.PersistentFlags().UintVar(&streamOpts.statsTop, "stats-top", 10, "Number of top items in the statistics")
+	streamCmd.PersistentFlags().DurationVar(&streamOpts.summaryInterval, "summary-interval", time.Minute, "Interval between statistics summaries")
+	streamCmd.PersistentFlags().BoolVar(&streamOpts.noDedup, "no-dedup", false, "Do not deduplicate the events received from several streams")
+}
+
+var streamReplaySubcommand = &cobra.Command{
+	Use:   "replay FILE",
+	Short: "Replay a recorded stream",
+	Long: `Replay a stream recorded with --record
+
+The recorded events are handled as if they were received from the server
+(without any network connection), so that templates, themes and hooks can
+be tested.
+The delays between the events are respected, unless the speed is set to 0.`,
+	Example: `  madonctl stream --record events.jsonl
+  madonctl stream replay events.jsonl --theme ansi
+  madonctl stream replay events.jsonl --speed 10x --command ./gateway.sh`,
+	RunE: streamReplayRunE,
+}
+
+var streamServeSubcommand = &cobra.Command{
+	Use:   "serve [user|local|public|direct|!LIST|:HASHTAG...] --listen ADDRESS",
+	Short: "Share a stream with local clients",
+	Long: `Share a stream with local clients
+
+The serve subcommand keeps a single connection to the server and
+re-broadcasts the events to any number of local clients, as JSON lines
+(in the --record format).  The clients can be madonctl instances started
+with --connect, or any program reading the socket.
+
+The listen address can be a unix socket (unix:PATH) or a TCP port on the
+loopback interface (localhost:PORT).  The events are not displayed.
+
+The unix socket is only accessible by the current user.  A TCP port can be
+reached by ANY local user, and the streams may contain your private home
+timeline and notifications: a shared token (--server-token, or the
+stream_server_token setting) is required, and the clients must send it on
+the first line.  The token is optional with unix sockets.`,
+	Example: `  madonctl stream serve --listen unix:/run/madonctl.sock
+  madonctl stream serve :golang local --listen localhost:7878 --server-token s3cr3t
+  madonctl stream --connect unix:/run/madonctl.sock --theme ansi
+  madonctl stream --connect localhost:7878 --server-token s3cr3t
+  socat - UNIX-CONNECT:/run/madonctl.sock | jq .message.event`,
+	RunE: streamServeRunE,
 }
 
 func streamRunE(cmd *cobra.Command, args []string) error {
-	streamName := "user"
-	var param string
-	var hashTagList []string
-
-	if len(args) > 0 {
-		if len(args) != 1 {
-			return errors.New("too many parameters")
-		}
-		arg := args[0]
-		switch arg {
-		case "", "user":
-		case "public":
-		case "direct":
-			streamName = arg
-		case "local":
-			streamName = "public:local"
-		default:
-			if arg[0] == '!' {
-				// List-based stream
-				streamName = "list"
-				param = arg[1:]
-				if len(param) == 0 {
-					return errors.New("empty list ID")
-				}
-				break
-			}
-			if arg[0] != ':' && arg[0] != '#' {
-				return errors.New("invalid argument")
-			}
-			streamName = "hashtag"
-			param = arg[1:]
-			if len(param) == 0 {
-				return errors.New("empty hashtag")
-			}
-			hashTagList = strings.Split(param, ",")
-			for i, h := range hashTagList {
-				if h[0] == ':' || h[0] == '#' {
-					hashTagList[i] = h[1:]
-				}
-				if h == "" {
-					return errors.New("empty hashtag")
+	if streamOpts.connect != "" {
+		if len(args) > 0 {
+			return errors.New("the streams cannot be selected with --connect")
+		}
+		// No connection to the Mastodon server is needed
+		return streamListen(nil, streamClientConnector(streamOpts.connect, streamServerToken()), nil, !streamOpts.noReconnect)
+	}
+
+	if len(args) == 0 {
+		args = []string{"user"}
+	}
+
+	var streams []mastodon.Stream
+	for _, arg := range args {
+		sl, err := parseStreamArg(arg)
+		if err != nil {
+			return err
+		}
+	STREAMS:
+		for _, st := range sl {
+			for _, prev := range streams {
+				if st == prev {
+					continue STREAMS // Skip duplicates
 				}
 			}
-			if len(hashTagList) > maximumHashtagStreamWS {
-				return errors.Errorf("too many hashtags, maximum is %d", maximumHashtagStreamWS)
-			}
+			streams = append(streams, st)
 		}
 	}
 
@@ -118,57 +276,75 @@ func streamRunE(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if streamOpts.idleTimeout > 0 {
+		// Ping the server so that an idle connection can be told from a
+		// dead one
+		gExtClient.SetStreamHeartbeat(streamOpts.idleTimeout/3, streamOpts.idleTimeout)
+	}
+
+	var conn *mastodon.StreamConn
+	connect := func(evChan chan<- mastodon.StreamEvent, stop chan bool) (done chan bool, err error) {
+		conn, done, err = streamConnect(streams, evChan, stop)
+		return
+	}
+	heartbeat := func() time.Time {
+		if conn == nil {
+			return time.Time{}
+		}
+		return conn.LastHeartbeat()
+	}
+	return streamListen(streams, connect, heartbeat, !streamOpts.noReconnect)
+}
+
+// streamConnector starts sending stream events to the evChan channel.
+// The returned channel is closed when there are no more events, e.g. when
+// the connection has been closed.  The stop channel is closed by the caller
+// to terminate the streaming.
+type streamConnector func(evChan chan<- mastodon.StreamEvent, stop chan bool) (chan bool, error)
+
+// streamListen dispatches the events from a stream connector to the
+// printer, the hooks and the webhook.
+// If reconnect is true, the connector is called again (with a backoff delay)
+// when the connection is lost.
+// The heartbeat function, if not nil, returns the time of the last heartbeat
+// received from the server (for the status reports).
+func streamListen(streams []mastodon.Stream, connect streamConnector, heartbeat func() time.Time, reconnect bool) error {
+	lf, err := loadLocalFilters()
+	if err != nil {
+		return err
+	}
+
 	var filterMap *map[string]bool
 	if streamOpts.notificationTypes != "" {
-		var err error
 		filterMap, err = buildFilterMap(streamOpts.notificationTypes)
 		if err != nil {
 			return err
 		}
 	}
 
-	evChan := make(chan madon.StreamEvent, 10)
-	stop := make(chan bool)
-	done := make(chan bool)
-	var err error
-
-	if streamName != "hashtag" || len(hashTagList) <= 1 { // Usual case: Only 1 stream
-		err = gClient.StreamListener(streamName, param, evChan, stop, done)
-	} else { // Several streams
-		n := len(hashTagList)
-		tagEvCh := make([]chan madon.StreamEvent, n)
-		tagDoneCh := make([]chan bool, n)
-		for i, t := range hashTagList {
-			if verbose {
-				errPrint("Launching listener for tag '%s'", t)
-			}
-			tagEvCh[i] = make(chan madon.StreamEvent)
-			tagDoneCh[i] = make(chan bool)
-			e := gClient.StreamListener(streamName, t, tagEvCh[i], stop, tagDoneCh[i])
-			if e != nil {
-				if i > 0 { // Close previous connections
-					close(stop)
-				}
-				err = e
-				break
-			}
-			// Forward events to main ev channel
-			go func(i int) {
-				for {
-					select {
-					case _, ok := <-tagDoneCh[i]:
-						if !ok { // end of streaming for this tag
-							done <- true
-							return
-						}
-					case ev := <-tagEvCh[i]:
-						evChan <- ev
-					}
-				}
-			}(i)
+	eventFilter, err := buildEventFilter(streamOpts.events)
+	if err != nil {
+		return err
+	}
+	if streamOpts.notificationsOnly {
+		eventFilter = map[string]bool{"notification": true}
+	}
+
+	var alerts []*alertRule
+	var alertCommands bool
+	if streamOpts.alertRules != "" {
+		if alerts, err = loadAlertRules(streamOpts.alertRules); err != nil {
+			return err
+		}
+		for _, r := range alerts {
+			alertCommands = alertCommands || r.Action == "command"
 		}
 	}
 
+	evChan := make(chan mastodon.StreamEvent, 10)
+	stop := make(chan bool)
+
+	done, err := connect(evChan, stop)
 	if err != nil {
 		errPrint("Error: %s", err.Error())
 		os.Exit(1)
@@ -177,8 +353,6 @@ func streamRunE(cmd *cobra.Command, args []string) error {
 	p, err := getPrinter()
 	if err != nil {
 		close(stop)
-		<-done
-		close(evChan)
 		errPrint("Error: %s", err.Error())
 		os.Exit(1)
 	}
@@ -186,59 +360,625 @@ func streamRunE(cmd *cobra.Command, args []string) error {
 	// Set up external command
 	p.setCommand(streamOpts.command)
 
-LISTEN:
-	for {
-		select {
-		case v, ok := <-done:
-			if !ok || v == true { // done is closed, end of streaming
-				break LISTEN
+	// Set up event hooks
+	hooks := map[string]string{
+		"update":        streamOpts.onUpdate,
+		"status.update": streamOpts.onUpdate,
+		"notification":  streamOpts.onNotification,
+		"delete":        streamOpts.onDelete,
+	}
+	var hr *hookRunner
+	var hookFailure chan error // nil unless hooks are used
+	if streamOpts.onUpdate != "" || streamOpts.onNotification != "" || streamOpts.onDelete != "" || alertCommands {
+		hr, err = newHookRunner(int(streamOpts.hookWorkers), streamOpts.hookTimeout,
+			streamOpts.hookFailure, int(streamOpts.hookRetries))
+		if err != nil {
+			close(stop)
+			return err
+		}
+		hookFailure = hr.failure
+	}
+
+	// Set up recorder
+	var rec *streamRecorder
+	if streamOpts.record != "" {
+		if rec, err = newStreamRecorder(streamOpts.record); err != nil {
+			close(stop)
+			return err
+		}
+		defer rec.close()
+	}
+
+	// Set up webhook
+	var wh *webhookForwarder
+	if streamOpts.webhook != "" {
+		secret := streamOpts.webhookSecret
+		if secret == "" {
+			secret = viper.GetString("webhook_secret")
+		}
+		wh, err = newWebhookForwarder(streamOpts.webhook, streamOpts.webhookHeaders,
+			secret, streamOpts.webhookSigHeader, streamOpts.webhookQueue,
+			int(streamOpts.webhookQueueSize))
+		if err != nil {
+			close(stop)
+			return err
+		}
+	}
+
+	// Set up local stream server
+	var srv *streamServer
+	if streamOpts.listen != "" {
+		if srv, err = newStreamServer(streamOpts.listen, streamServerToken()); err != nil {
+			close(stop)
+			return err
+		}
+		defer srv.close()
+		if verbose {
+			errPrint("Stream server listening on %s", streamOpts.listen)
+		}
+	}
+
+	// Set up analytics
+	var sa *streamAnalytics
+	var summaryTick <-chan time.Time // nil unless --stats is used
+	if streamOpts.stats {
+		if streamOpts.summaryInterval <= 0 {
+			close(stop)
+			return errors.New("the summary interval must be positive")
+		}
+		sa = newStreamAnalytics(streamOpts.statsWindow, int(streamOpts.statsTop))
+		ticker := time.NewTicker(streamOpts.summaryInterval)
+		defer ticker.Stop()
+		summaryTick = ticker.C
+	}
+
+	// Last IDs seen (the status IDs by stream label), used to backfill the
+	// events missed while reconnecting
+	lastStatusIDs := make(map[string]madon.ActivityID)
+	var lastNotifID madon.ActivityID
+	// Events handled since the last reconnection, to drop the events
+	// received both from the backfill and from the stream
+	var backfillSeen *lruSet
+	// Recent events, to drop the duplicates coming from several streams
+	var dedup *lruSet
+	if !streamOpts.noDedup {
+		dedup = newLRUSet(streamDedupSize)
+	}
+	isDuplicate := func(key string) bool {
+		if dedup != nil && dedup.seen(key) {
+			if verbose {
+				errPrint("Duplicate event dropped: %s", key)
 			}
-		case ev := <-evChan:
-			switch ev.Event {
-			case "error":
-				if ev.Error != nil {
-					if ev.Error == io.ErrUnexpectedEOF {
-						errPrint("The stream connection was unexpectedly closed")
-						continue
-					}
-					errPrint("Error event: [%s] %s", ev.Event, ev.Error)
-					continue
+			return true
+		}
+		return false
+	}
+
+	jsonEnc := json.NewEncoder(os.Stdout)
+	jsonEnc.SetEscapeHTML(false)
+
+	// raiseAlerts runs the actions of the alert rules matching a status
+	alertColor := alertColors()
+	raiseAlerts := func(ev mastodon.StreamEvent, s *mastodon.Status, stream string) error {
+		rules, text := checkAlerts(alerts, s.MadonStatus())
+		for _, r := range rules {
+			ar := newAlertRecord(r, s.MadonStatus(), text)
+			switch r.Action {
+			case "print":
+				if !streamOpts.eventsJSON {
+					printAlert(os.Stdout, r, ar, alertColor)
+					break
+				}
+				ar.Status = s.MadonStatus()
+				env := streamEnvelope{Event: "alert", Stream: stream, ReceivedAt: ev.ReceivedAt, Payload: ar}
+				if err := jsonEnc.Encode(env); err != nil {
+					return err
 				}
+			case "command":
+				input, err := hookInput(p, s)
+				if err != nil {
+					return err
+				}
+				hr.submit(r.Command, input, append(hookEnv(ev, stream), "MADONCTL_ALERT_RULE="+r.Name))
+			case "file":
+				if err := appendAlert(r, ar); err != nil {
+					errPrint("Error: %s", err.Error())
+				}
+			}
+		}
+		return nil
+	}
+
+	// output displays the event object, or the event envelope with
+	// --events-json.  It also runs the hooks and forwards the event to the
+	// webhook.
+	output := func(ev mastodon.StreamEvent, obj interface{}) error {
+		stream := ""
+		if ev.Stream.Name != "" {
+			stream = streamLabel(ev.Stream)
+		} else if len(streams) == 1 {
+			stream = streamLabel(streams[0])
+		}
+		env := streamEnvelope{Event: ev.Event, Stream: stream, ReceivedAt: ev.ReceivedAt, Payload: obj}
+
+		if command := hooks[ev.Event]; command != "" && hr != nil {
+			input, err := hookInput(p, obj)
+			if err != nil {
+				return err
+			}
+			hr.submit(command, input, hookEnv(ev, stream))
+		}
+
+		if wh != nil && ev.Event != "error" {
+			if err := wh.enqueue(env); err != nil {
+				errPrint("Error: %s", err.Error())
+			}
+		}
+
+		if srv != nil && ev.Event != "error" {
+			return nil // The events are only broadcast
+		}
+
+		if alerts != nil && ev.Event != "error" {
+			if s, ok := obj.(*mastodon.Status); ok && ev.Event == "update" {
+				if err := raiseAlerts(ev, s, stream); err != nil {
+					return err
+				}
+			}
+			if sa == nil {
+				return nil // Only the alerts are displayed
+			}
+		}
+
+		if sa != nil && ev.Event != "error" {
+			if s, ok := obj.(*mastodon.Status); ok && ev.Event == "update" {
+				sa.add(s, time.Now())
+			}
+			return nil // Only the summaries are displayed
+		}
+
+		if streamOpts.eventsJSON {
+			return jsonEnc.Encode(env)
+		}
+
+		switch ev.Event {
+		case "error":
+			if ev.Error == nil {
 				errPrint("Event: [%s]", ev.Event)
-			case "update":
-				if streamOpts.notificationsOnly {
-					continue
+			} else if ev.Error == io.ErrUnexpectedEOF {
+				errPrint("The stream connection was unexpectedly closed")
+			} else {
+				errPrint("Error event: [%s] %s", ev.Event, ev.Error)
+			}
+		case "delete":
+			errPrint("Event: [%s] Status %s was deleted", ev.Event, ev.Data.(string))
+		case "announcement.delete":
+			errPrint("Event: [%s] Announcement %s was deleted", ev.Event, ev.Data.(string))
+		case "filters_changed":
+			errPrint("Event: [%s] The server-side filters have been modified", ev.Event)
+		default:
+			return p.printObj(obj)
+		}
+		return nil
+	}
+
+	handleEvent := func(ev mastodon.StreamEvent) error {
+		if ev.Event != "error" && eventFilter != nil && !eventFilter[ev.Event] {
+			return nil
+		}
+
+		switch ev.Event {
+		case "error":
+			var msg interface{}
+			if ev.Error != nil {
+				msg = ev.Error.Error()
+			}
+			return output(ev, msg)
+		case "update", "status.update":
+			s := ev.Data.(mastodon.Status)
+			// Originating stream (unknown if the server does not tell
+			// and several streams are subscribed)
+			label := ""
+			if ev.Stream.Name != "" {
+				label = streamLabel(ev.Stream)
+			} else if len(streams) == 1 {
+				label = streamLabel(streams[0])
+			}
+			// Use the server-side filters relevant to the originating stream
+			filterContext := ""
+			if label != "" {
+				filterContext = timelineFilterContext(label)
+				if idGreater(s.ID, lastStatusIDs[label]) {
+					lastStatusIDs[label] = s.ID
 				}
-				s := ev.Data.(madon.Status)
-				if err = p.printObj(&s); err != nil {
-					break LISTEN
+			}
+			// The key contains the stream, so that the statuses received
+			// from several streams are not dropped here
+			if backfillSeen != nil && ev.Event == "update" &&
+				backfillSeen.seen("update:"+label+":"+s.ID) {
+				return nil
+			}
+			key := ev.Event + ":" + s.ID
+			if s.EditedAt != nil { // Several edits of the same status
+				key += ":" + s.EditedAt.String()
+			}
+			if isDuplicate(key) {
+				return nil
+			}
+			if lf.matchStatus(s.MadonStatus()) {
+				if verbose {
+					errPrint("Local filters: status %s dropped", s.ID)
+				}
+				return nil
+			}
+			s.ApplyFilterContext(filterContext)
+			return output(ev, &s)
+		case "notification":
+			n := ev.Data.(madon.Notification)
+			if idGreater(n.ID, lastNotifID) {
+				lastNotifID = n.ID
+			}
+			if backfillSeen != nil && backfillSeen.seen("notification:"+n.ID) {
+				return nil
+			}
+			if filterMap != nil && !(*filterMap)[n.Type] {
+				return nil
+			}
+			if lf.matchNotification(&n) {
+				if verbose {
+					errPrint("Local filters: notification %s dropped", n.ID)
 				}
-				continue
-			case "notification":
-				n := ev.Data.(madon.Notification)
-				if filterMap != nil && !(*filterMap)[n.Type] {
-					continue
+				return nil
+			}
+			return output(ev, &n)
+		case "conversation":
+			c := ev.Data.(mastodon.Conversation)
+			if c.LastStatus != nil && lf.matchStatus(c.LastStatus.MadonStatus()) {
+				if verbose {
+					errPrint("Local filters: conversation %s dropped", c.ID)
 				}
-				if p.printObj(&n); err != nil {
+				return nil
+			}
+			return output(ev, &c)
+		case "announcement":
+			a := ev.Data.(mastodon.Announcement)
+			return output(ev, &a)
+		case "announcement.reaction":
+			r := ev.Data.(mastodon.AnnouncementReactionEvent)
+			return output(ev, &r)
+		case "delete":
+			if isDuplicate("delete:" + ev.Data.(string)) {
+				return nil
+			}
+			return output(ev, ev.Data)
+		case "announcement.delete":
+			return output(ev, ev.Data)
+		case "filters_changed":
+			return output(ev, nil)
+		default:
+			errPrint("Unhandled event: [%s] %T", ev.Event, ev.Data)
+		}
+		return nil
+	}
+
+	// printSummary displays the analytics summary
+	printSummary := func() error {
+		sum := sa.summary(time.Now())
+		if streamOpts.eventsJSON {
+			return jsonEnc.Encode(streamEnvelope{Event: "summary", ReceivedAt: sum.Time, Payload: sum})
+		}
+		switch getOutputFormat() {
+		case "plain", "theme":
+			sum.print(os.Stdout)
+			return nil
+		}
+		return p.printObj(sum)
+	}
+
+	// Set up status reports
+	health := newStreamHealth(heartbeat)
+	var statsTick <-chan time.Time // nil unless reports are enabled
+	if streamOpts.statsInterval > 0 {
+		ticker := time.NewTicker(streamOpts.statsInterval)
+		defer ticker.Stop()
+		statsTick = ticker.C
+	}
+
+	// dispatch records and handles an event
+	dispatch := func(ev mastodon.StreamEvent) error {
+		health.count(ev)
+		if rec != nil {
+			if err := rec.record(ev); err != nil {
+				return err
+			}
+		}
+		if srv != nil {
+			if err := srv.broadcast(ev); err != nil {
+				return err
+			}
+		}
+		return handleEvent(ev)
+	}
+
+	delay := streamReconnectMinDelay
+
+LISTEN:
+	for {
+		select {
+		case <-done: // End of streaming
+			close(stop)
+			// Handle the events that are still in the channel buffer
+			for len(evChan) > 0 {
+				if err = dispatch(<-evChan); err != nil {
 					break LISTEN
 				}
-				continue
-			case "delete":
-				if streamOpts.notificationsOnly {
-					continue
+			}
+			if !reconnect {
+				break LISTEN
+			}
+			// Reconnect, with an exponential backoff
+			for {
+				wait := delay + time.Duration(rand.Int63n(int64(delay)/2+1))
+				errPrint("The stream connection was closed, reconnecting in %v...", wait.Round(time.Second))
+				time.Sleep(wait)
+				if delay *= 2; delay > streamReconnectMaxDelay {
+					delay = streamReconnectMaxDelay
+				}
+				stop = make(chan bool)
+				if done, err = connect(evChan, stop); err == nil {
+					break
 				}
-				// TODO PrintObj ?
-				errPrint("Event: [%s] Status %s was deleted", ev.Event, ev.Data.(string))
-			default:
-				errPrint("Unhandled event: [%s] %T", ev.Event, ev.Data)
+				errPrint("Error: %s", err.Error())
+			}
+			health.reconnects++
+			if verbose {
+				errPrint("Reconnected to the stream server")
 			}
+			// Fetch the events that have been missed
+			backfillSeen = newLRUSet(streamDedupSize)
+			err = streamBackfill(streams, lastStatusIDs, lastNotifID, dispatch)
+			if err != nil {
+				close(stop)
+				break LISTEN
+			}
+		case <-statsTick:
+			report := health.report()
+			if !streamOpts.eventsJSON {
+				errPrint("%s", report)
+				break
+			}
+			env := streamEnvelope{Event: "stats", ReceivedAt: time.Now(), Payload: report}
+			if err = jsonEnc.Encode(env); err != nil {
+				close(stop)
+				break LISTEN
+			}
+		case <-summaryTick:
+			if err = printSummary(); err != nil {
+				close(stop)
+				break LISTEN
+			}
+		case err = <-hookFailure: // With the "abort" policy
+			close(stop)
+			break LISTEN
+		case ev := <-evChan:
+			if ev.Event != "error" {
+				delay = streamReconnectMinDelay // The connection is working
+			}
+			if err = dispatch(ev); err != nil {
+				close(stop)
+				break LISTEN
+			}
+		}
+	}
+	if sa != nil && err == nil {
+		err = printSummary() // Final summary
+	}
+	if wh != nil {
+		wh.close()
+	}
+	if hr != nil {
+		if hErr := hr.wait(); err == nil {
+			err = hErr
 		}
 	}
-	close(stop)
-	close(evChan)
 	if err != nil {
 		errPrint("Error: %s", err.Error())
 		os.Exit(1)
 	}
 	return nil
 }
+
+// streamEnvelope is the JSON representation of a stream event
+// (with --events-json)
+type streamEnvelope struct {
+	Event      string      `json:"event"`
+	Stream     string      `json:"stream,omitempty"`
+	ReceivedAt time.Time   `json:"received_at"`
+	Payload    interface{} `json:"payload"`
+}
+
+// streamEvents contains the event names supported by the stream command
+var streamEvents = []string{
+	"update", "status.update", "notification", "delete", "conversation",
+	"announcement", "announcement.reaction", "announcement.delete",
+	"filters_changed",
+}
+
+// buildEventFilter returns the set of the events to be displayed from a
+// comma-separated list of event names.  It returns nil if the list is empty.
+func buildEventFilter(events string) (map[string]bool, error) {
+	if events == "" {
+		return nil, nil
+	}
+	eventFilter := make(map[string]bool)
+EVENTS:
+	for _, e := range strings.Split(events, ",") {
+		for _, name := range streamEvents {
+			if e == name {
+				eventFilter[e] = true
+				continue EVENTS
+			}
+		}
+		return nil, errors.Errorf("unknown event: '%s'", e)
+	}
+	return eventFilter, nil
+}
+
+// parseStreamArg returns the stream(s) corresponding to a stream command
+// argument
+func parseStreamArg(arg string) ([]mastodon.Stream, error) {
+	switch arg {
+	case "", "user", "home":
+		return []mastodon.Stream{{Name: "user"}}, nil
+	case "public", "direct":
+		return []mastodon.Stream{{Name: arg}}, nil
+	case "local":
+		return []mastodon.Stream{{Name: "public:local"}}, nil
+	}
+
+	if arg[0] == '!' {
+		// List-based stream
+		if len(arg) == 1 {
+			return nil, errors.New("empty list ID")
+		}
+		return []mastodon.Stream{{Name: "list", Param: arg[1:]}}, nil
+	}
+	if arg[0] != ':' && arg[0] != '#' {
+		return nil, errors.Errorf("invalid argument '%s'", arg)
+	}
+
+	var streams []mastodon.Stream
+	for _, h := range strings.Split(arg[1:], ",") {
+		if h != "" && (h[0] == ':' || h[0] == '#') {
+			h = h[1:]
+		}
+		if h == "" {
+			return nil, errors.New("empty hashtag")
+		}
+		streams = append(streams, mastodon.Stream{Name: "hashtag", Param: h})
+	}
+	return streams, nil
+}
+
+// streamLabel returns the stream name as used in the command arguments
+// (e.g. "local", "!42" or ":golang")
+func streamLabel(s mastodon.Stream) string {
+	switch s.Name {
+	case "public:local":
+		return "local"
+	case "list":
+		return "!" + s.Param
+	case "hashtag":
+		return ":" + s.Param
+	}
+	if s.Param != "" {
+		return s.Name + ":" + s.Param
+	}
+	return s.Name
+}
+
+// streamConnect opens a stream connection subscribed to the streams.
+// The events are sent to the evChan channel.  The returned channel is closed
+// when the connection has been closed.
+func streamConnect(streams []mastodon.Stream, evChan chan<- mastodon.StreamEvent, stop chan bool) (*mastodon.StreamConn, chan bool, error) {
+	if verbose {
+		var labels []string
+		for _, s := range streams {
+			labels = append(labels, streamLabel(s))
+		}
+		errPrint("Subscribing to stream(s): %s", strings.Join(labels, " "))
+	}
+	done := make(chan bool)
+	conn, err := gExtClient.StreamListener(streams, evChan, stop, done)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, done, nil
+}
+
+// streamBackfill fetches the statuses and notifications newer than the last
+// ones seen, and passes them (oldest first) to the handler as stream events.
+// The last status IDs are indexed by stream label; each timeline is fetched
+// from the last status seen on its own stream.  The direct stream (which
+// sends conversation events) is not backfilled.
+// API errors are reported but do not stop the backfill; the first handler
+// error is returned.
+func streamBackfill(streams []mastodon.Stream, lastStatusIDs map[string]madon.ActivityID, lastNotifID madon.ActivityID, handler func(mastodon.StreamEvent) error) error {
+	var events []mastodon.StreamEvent
+	var userStream bool
+
+	for _, st := range streams {
+		var local bool
+		tl := streamLabel(st)
+		sinceID := lastStatusIDs[tl]
+		switch st.Name {
+		case "user":
+			tl, userStream = "home", true
+		case "public:local":
+			tl, local = "public", true
+		case "public", "list", "hashtag":
+		default:
+			continue // No timeline for this stream
+		}
+		if sinceID == "" {
+			continue // Nothing seen yet on this stream
+		}
+		lopt := &madon.LimitParams{Limit: streamBackfillPageSize, SinceID: sinceID}
+		for i := 0; i < streamBackfillMaxPages; i++ {
+			page, err := gExtClient.GetTimelines(tl, local, false, lopt)
+			if err != nil {
+				errPrint("Error: cannot backfill timeline: %s", err.Error())
+				break
+			}
+			for _, s := range page {
+				events = append(events, mastodon.StreamEvent{Event: "update", Stream: st, ReceivedAt: time.Now(), Data: s})
+			}
+			if len(page) < lopt.Limit {
+				break
+			}
+			lopt.MaxID = page[len(page)-1].ID
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return idGreater(events[j].Data.(mastodon.Status).ID, events[i].Data.(mastodon.Status).ID)
+	})
+
+	if userStream && lastNotifID != "" {
+		var nl []madon.Notification
+		lopt := &madon.LimitParams{Limit: streamBackfillPageSize, SinceID: lastNotifID}
+		for i := 0; i < streamBackfillMaxPages; i++ {
+			page, err := gClient.GetNotifications(nil, lopt)
+			if err != nil {
+				errPrint("Error: cannot backfill notifications: %s", err.Error())
+				break
+			}
+			nl = append(nl, page...)
+			if len(page) < lopt.Limit {
+				break
+			}
+			lopt.MaxID = page[len(page)-1].ID
+		}
+		for i := len(nl) - 1; i >= 0; i-- {
+			events = append(events, mastodon.StreamEvent{Event: "notification", Stream: mastodon.Stream{Name: "user"}, ReceivedAt: time.Now(), Data: nl[i]})
+		}
+	}
+
+	if verbose {
+		errPrint("Backfill: %d event(s) fetched", len(events))
+	}
+
+	for _, ev := range events {
+		if err := handler(ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// idGreater returns true if the ID a is greater (i.e. more recent) than b
+func idGreater(a, b madon.ActivityID) bool {
+	if len(a) != len(b) {
+		return len(a) > len(b)
+	}
+	return a > b
+}
@@ -0,0 +1,187 @@
+// Copyright © 2026 Mikael Berthe <mikael@lilotux.net>
+//
+// Licensed under the MIT license.
+// Please see the LICENSE file is this directory.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/McKael/madonctl/v3/mastodon"
+)
+
+// streamAnalytics aggregates the statuses of a stream in a sliding window
+// of one-minute buckets (stream --stats)
+type streamAnalytics struct {
+	window  time.Duration
+	top     int
+	buckets []*streamAnalyticsBucket // Oldest first
+}
+
+type streamAnalyticsBucket struct {
+	minute    time.Time
+	count     int
+	hashtags  map[string]int
+	domains   map[string]int
+	languages map[string]int
+	posters   map[string]int
+}
+
+// streamStatsCount is a counter in a stream summary
+type streamStatsCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// streamStatsMinute is the number of statuses received during a minute
+type streamStatsMinute struct {
+	Minute time.Time `json:"minute"`
+	Count  int       `json:"count"`
+}
+
+// streamSummary is a snapshot of the stream analytics
+type streamSummary struct {
+	Time         time.Time           `json:"time"`
+	Window       string              `json:"window"`
+	Statuses     int                 `json:"statuses"`
+	PerMinute    []streamStatsMinute `json:"per_minute"`
+	TopHashtags  []streamStatsCount  `json:"top_hashtags"`
+	TopDomains   []streamStatsCount  `json:"top_domains"`
+	TopLanguages []streamStatsCount  `json:"top_languages"`
+	TopPosters   []streamStatsCount  `json:"top_posters"`
+}
+
+func newStreamAnalytics(window time.Duration, top int) *streamAnalytics {
+	if window < time.Minute {
+		window = time.Minute
+	}
+	return &streamAnalytics{window: window, top: top}
+}
+
+// expire removes the buckets that are out of the window
+func (sa *streamAnalytics) expire(now time.Time) {
+	limit := now.Truncate(time.Minute).Add(-sa.window + time.Minute)
+	i := 0
+	for i < len(sa.buckets) && sa.buckets[i].minute.Before(limit) {
+		i++
+	}
+	sa.buckets = sa.buckets[i:]
+}
+
+// add counts a status received at time t
+func (sa *streamAnalytics) add(s *mastodon.Status, t time.Time) {
+	minute := t.Truncate(time.Minute)
+	var b *streamAnalyticsBucket
+	if n := len(sa.buckets); n > 0 && !sa.buckets[n-1].minute.Before(minute) {
+		b = sa.buckets[n-1] // Current minute (or clock skew)
+	} else {
+		b = &streamAnalyticsBucket{
+			minute:    minute,
+			hashtags:  make(map[string]int),
+			domains:   make(map[string]int),
+			languages: make(map[string]int),
+			posters:   make(map[string]int),
+		}
+		sa.buckets = append(sa.buckets, b)
+	}
+	sa.expire(t)
+
+	if s.Reblog != nil {
+		s = s.Reblog // Count the original status
+	}
+	b.count++
+	for _, tag := range s.Tags {
+		b.hashtags[strings.ToLower(tag.Name)]++
+	}
+	if s.Language != nil && *s.Language != "" {
+		b.languages[*s.Language]++
+	}
+	if s.Account != nil {
+		address, domain := accountAddress(s.Account)
+		b.posters[address]++
+		if domain != "" {
+			b.domains[domain]++
+		}
+	}
+}
+
+// summary returns a snapshot of the current window
+func (sa *streamAnalytics) summary(now time.Time) streamSummary {
+	sa.expire(now)
+
+	hashtags := make(map[string]int)
+	domains := make(map[string]int)
+	languages := make(map[string]int)
+	posters := make(map[string]int)
+	merge := func(dst, src map[string]int) {
+		for k, n := range src {
+			dst[k] += n
+		}
+	}
+
+	sum := streamSummary{Time: now, Window: sa.window.String(), PerMinute: []streamStatsMinute{}}
+	for _, b := range sa.buckets {
+		sum.Statuses += b.count
+		sum.PerMinute = append(sum.PerMinute, streamStatsMinute{Minute: b.minute, Count: b.count})
+		merge(hashtags, b.hashtags)
+		merge(domains, b.domains)
+		merge(languages, b.languages)
+		merge(posters, b.posters)
+	}
+	sum.TopHashtags = topCounts(hashtags, sa.top)
+	sum.TopDomains = topCounts(domains, sa.top)
+	sum.TopLanguages = topCounts(languages, sa.top)
+	sum.TopPosters = topCounts(posters, sa.top)
+	return sum
+}
+
+// topCounts returns the n highest counters, sorted by decreasing count
+func topCounts(m map[string]int, n int) []streamStatsCount {
+	list := []streamStatsCount{}
+	for k, c := range m {
+		list = append(list, streamStatsCount{Name: k, Count: c})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return list[i].Name < list[j].Name
+	})
+	if n > 0 && len(list) > n {
+		list = list[:n]
+	}
+	return list
+}
+
+// print writes the summary as a text table
+func (sum streamSummary) print(w io.Writer) {
+	fmt.Fprintf(w, "- Stream summary at %s (last %s): %d status(es)\n",
+		sum.Time.Local().Format("2006-01-02 15:04:05"), sum.Window, sum.Statuses)
+
+	var minutes []string
+	for _, m := range sum.PerMinute {
+		minutes = append(minutes, fmt.Sprintf("%s=%d", m.Minute.Local().Format("15:04"), m.Count))
+	}
+	if len(minutes) > 0 {
+		fmt.Fprintf(w, "  Per minute: %s\n", strings.Join(minutes, " "))
+	}
+
+	section := func(title string, list []streamStatsCount) {
+		if len(list) == 0 {
+			return
+		}
+		fmt.Fprintf(w, "  %s:\n", title)
+		for _, c := range list {
+			fmt.Fprintf(w, "    %6d  %s\n", c.Count, c.Name)
+		}
+	}
+	section("Top hashtags", sum.TopHashtags)
+	section("Top domains", sum.TopDomains)
+	section("Top languages", sum.TopLanguages)
+	section("Top posters", sum.TopPosters)
+}
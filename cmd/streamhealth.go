@@ -0,0 +1,99 @@
+// Copyright © 2026 Mikael Berthe <mikael@lilotux.net>
+//
+// Licensed under the MIT license.
+// Please see the LICENSE file is this directory.
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/McKael/madonctl/v3/mastodon"
+)
+
+// streamHealth keeps track of the stream activity, for the periodic
+// status reports (--stats-interval)
+type streamHealth struct {
+	start      time.Time
+	events     map[string]int
+	reconnects int
+	lastEvent  time.Time
+	heartbeat  func() time.Time // Last heartbeat from the server (may be nil)
+}
+
+// streamHealthReport is the JSON representation of a status report
+type streamHealthReport struct {
+	Uptime           float64        `json:"uptime_seconds"`
+	Events           map[string]int `json:"events"`
+	Reconnects       int            `json:"reconnects"`
+	LastEventAge     *float64       `json:"last_event_age_seconds"`
+	LastHeartbeatAge *float64       `json:"last_heartbeat_age_seconds"`
+}
+
+func newStreamHealth(heartbeat func() time.Time) *streamHealth {
+	return &streamHealth{
+		start:     time.Now(),
+		events:    make(map[string]int),
+		heartbeat: heartbeat,
+	}
+}
+
+// count records a received event
+func (h *streamHealth) count(ev mastodon.StreamEvent) {
+	h.events[ev.Event]++
+	if ev.Event != "error" {
+		h.lastEvent = time.Now()
+	}
+}
+
+// report returns the current status report
+func (h *streamHealth) report() streamHealthReport {
+	now := time.Now()
+	age := func(t time.Time) *float64 {
+		if t.IsZero() {
+			return nil
+		}
+		a := now.Sub(t).Round(time.Second).Seconds()
+		return &a
+	}
+
+	r := streamHealthReport{
+		Uptime:       now.Sub(h.start).Round(time.Second).Seconds(),
+		Events:       make(map[string]int, len(h.events)),
+		Reconnects:   h.reconnects,
+		LastEventAge: age(h.lastEvent),
+	}
+	for e, n := range h.events {
+		r.Events[e] = n
+	}
+	if h.heartbeat != nil {
+		r.LastHeartbeatAge = age(h.heartbeat())
+	}
+	return r
+}
+
+// String returns a one-line version of the report
+func (r streamHealthReport) String() string {
+	ageString := func(a *float64) string {
+		if a == nil {
+			return "never"
+		}
+		return fmt.Sprintf("%v ago", time.Duration(*a)*time.Second)
+	}
+
+	var events []string
+	for e, n := range r.Events {
+		events = append(events, fmt.Sprintf("%s=%d", e, n))
+	}
+	sort.Strings(events)
+	if len(events) == 0 {
+		events = []string{"none"}
+	}
+
+	return fmt.Sprintf("Stream stats: uptime %v, events: %s, reconnects: %d, last event: %s, last heartbeat: %s",
+		time.Duration(r.Uptime)*time.Second, strings.Join(events, " "), r.Reconnects,
+		ageString(r.LastEventAge), ageString(r.LastHeartbeatAge))
+}
@@ -0,0 +1,205 @@
+// Copyright © 2026 Mikael Berthe <mikael@lilotux.net>
+//
+// Licensed under the MIT license.
+// Please see the LICENSE file is this directory.
+
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/McKael/madonctl/v3/mastodon"
+)
+
+// Maximum size of a recorded event line
+const streamRecordMaxLineSize = 16 * 1024 * 1024
+
+// streamRecord is a recorded stream event (one JSON line per event)
+type streamRecord struct {
+	ReceivedAt time.Time       `json:"received_at"`
+	Message    json.RawMessage `json:"message"` // Raw streaming API message
+}
+
+// streamRecorder appends the stream events to a file
+type streamRecorder struct {
+	f *os.File
+}
+
+func newStreamRecorder(filename string) (*streamRecorder, error) {
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot open record file")
+	}
+	return &streamRecorder{f: f}, nil
+}
+
+// record writes an event to the record file
+func (r *streamRecorder) record(ev mastodon.StreamEvent) error {
+	line, err := encodeStreamRecord(ev)
+	if err != nil || line == nil {
+		return err
+	}
+	if _, err := r.f.Write(line); err != nil {
+		return errors.Wrap(err, "cannot record event")
+	}
+	return nil
+}
+
+func (r *streamRecorder) close() error {
+	return r.f.Close()
+}
+
+// encodeStreamRecord returns the record line (with a trailing newline) of
+// a stream event.
+// The events without a raw server message (e.g. backfilled statuses) are
+// encoded the same way as the streaming API does.  Connection errors are not
+// recorded: nil is returned.
+func encodeStreamRecord(ev mastodon.StreamEvent) ([]byte, error) {
+	msg := ev.Raw
+	if msg == nil {
+		if ev.Event == "error" {
+			return nil, nil
+		}
+		m := map[string]interface{}{"event": ev.Event}
+		if ev.Stream.Name != "" {
+			stream := []string{ev.Stream.Name}
+			if ev.Stream.Param != "" {
+				stream = append(stream, ev.Stream.Param)
+			}
+			m["stream"] = stream
+		}
+		if ev.Data != nil {
+			payload, ok := ev.Data.(string)
+			if !ok {
+				b, err := json.Marshal(ev.Data)
+				if err != nil {
+					return nil, errors.Wrap(err, "cannot encode event")
+				}
+				payload = string(b)
+			}
+			m["payload"] = payload
+		}
+		var err error
+		if msg, err = json.Marshal(m); err != nil {
+			return nil, errors.Wrap(err, "cannot encode event")
+		}
+	}
+
+	line, err := json.Marshal(streamRecord{ReceivedAt: ev.ReceivedAt, Message: msg})
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot encode event")
+	}
+	return append(line, '\n'), nil
+}
+
+// parseReplaySpeed parses a replay speed factor (e.g. "10x" or "0.5")
+func parseReplaySpeed(s string) (float64, error) {
+	speed, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToLower(s), "x"), 64)
+	if err != nil || speed < 0 {
+		return 0, errors.Errorf("invalid replay speed '%s'", s)
+	}
+	return speed, nil
+}
+
+// streamReplayConnector returns a stream connector that reads the events
+// from a record file.  The delays between the events are divided by the
+// speed factor; there is no delay if speed is 0.
+func streamReplayConnector(filename string, speed float64) streamConnector {
+	return func(evChan chan<- mastodon.StreamEvent, stop chan bool) (chan bool, error) {
+		f, err := os.Open(filename)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot open record file")
+		}
+
+		done := make(chan bool)
+		go readStreamRecords(f, speed, evChan, stop, done)
+		return done, nil
+	}
+}
+
+// readStreamRecords reads the recorded events from r and sends them to the
+// evChan channel, until the end of the input or until the stop channel is
+// closed.  The reader is closed and the done channel is closed when it
+// terminates.
+func readStreamRecords(r io.ReadCloser, speed float64, evChan chan<- mastodon.StreamEvent, stop, done chan bool) {
+	defer close(done)
+	defer r.Close()
+
+	// Unblock the reader when the stop channel is closed
+	go func() {
+		select {
+		case <-stop:
+			r.Close()
+		case <-done:
+		}
+	}()
+
+	send := func(ev mastodon.StreamEvent) bool {
+		select {
+		case evChan <- ev:
+			return true
+		case <-stop:
+			return false
+		}
+	}
+
+	var last time.Time
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, streamRecordMaxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		var rec streamRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			e := errors.Wrapf(err, "invalid record (line %d)", line)
+			if !send(mastodon.StreamEvent{Event: "error", ReceivedAt: time.Now(), Error: e}) {
+				return
+			}
+			continue
+		}
+
+		if speed > 0 && !last.IsZero() && rec.ReceivedAt.After(last) {
+			select {
+			case <-stop:
+				return
+			case <-time.After(time.Duration(float64(rec.ReceivedAt.Sub(last)) / speed)):
+			}
+		}
+		last = rec.ReceivedAt
+
+		ev := mastodon.DecodeStreamMessage(rec.Message)
+		ev.ReceivedAt = rec.ReceivedAt
+		if !send(ev) {
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		select {
+		case <-stop: // The reader has been closed
+		default:
+			e := errors.Wrap(err, "cannot read records")
+			send(mastodon.StreamEvent{Event: "error", ReceivedAt: time.Now(), Error: e})
+		}
+	}
+}
+
+func streamReplayRunE(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return errors.New("wrong usage: please provide a single record file")
+	}
+
+	speed, err := parseReplaySpeed(streamOpts.speed)
+	if err != nil {
+		return err
+	}
+
+	// No connection to the server is needed
+	return streamListen(nil, streamReplayConnector(args[0], speed), nil, false)
+}
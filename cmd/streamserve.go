@@ -0,0 +1,334 @@
+// Copyright © 2026 Mikael Berthe <mikael@lilotux.net>
+//
+// Licensed under the MIT license.
+// Please see the LICENSE file is this directory.
+
+package cmd
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/McKael/madonctl/v3/mastodon"
+)
+
+// Maximum number of events queued for a local client; slower clients are
+// disconnected.
+const streamServerClientQueueSize = 1000
+
+// Maximum time for a client to send its token
+const streamServerAuthTimeout = 5 * time.Second
+
+// streamServer re-broadcasts the stream events to local clients.
+// The events are sent as JSON lines, in the --record format.
+// When a token is set, the clients must send it (followed by a newline)
+// before they receive any event.  A token is required for TCP addresses,
+// since any local user can connect to them.
+type streamServer struct {
+	ln      net.Listener
+	network string
+	address string
+	token   string
+
+	mu      sync.Mutex
+	clients map[*streamServerClient]bool
+}
+
+type streamServerClient struct {
+	conn net.Conn
+	out  chan []byte
+}
+
+// parseStreamAddress parses a local stream server address
+// Unix sockets are prefixed with "unix:"; TCP addresses must use a loopback
+// interface ("localhost:PORT", "127.0.0.1:PORT", ":PORT"...).
+func parseStreamAddress(address string) (network, addr string, err error) {
+	if strings.HasPrefix(address, "unix:") {
+		addr = strings.TrimPrefix(address, "unix:")
+		if addr == "" {
+			return "", "", errors.New("empty unix socket path")
+		}
+		return "unix", addr, nil
+	}
+
+	addr = strings.TrimPrefix(address, "tcp:")
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "invalid address '%s'", address)
+	}
+	switch host {
+	case "":
+		host = "127.0.0.1"
+	case "localhost":
+	default:
+		if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+			return "", "", errors.Errorf("invalid address '%s': only local addresses are allowed", address)
+		}
+	}
+	return "tcp", net.JoinHostPort(host, port), nil
+}
+
+// newStreamServer starts listening for local clients
+func newStreamServer(address, token string) (*streamServer, error) {
+	network, addr, err := parseStreamAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	if network == "tcp" && token == "" {
+		return nil, errors.New("a token is required for TCP addresses (--server-token)")
+	}
+
+	var ln net.Listener
+	if network == "unix" {
+		ln, err = listenUnixPrivate(addr)
+	} else {
+		ln, err = net.Listen(network, addr)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot start the stream server")
+	}
+
+	srv := &streamServer{
+		ln:      ln,
+		network: network,
+		address: addr,
+		token:   token,
+		clients: make(map[*streamServerClient]bool),
+	}
+	go srv.accept()
+	return srv, nil
+}
+
+// listenUnixPrivate listens on a unix socket only accessible by the user
+// The socket is created in a private (0700) temporary directory and moved
+// to its final path once its permissions have been restricted, so that
+// other users cannot connect in the meantime.
+func listenUnixPrivate(addr string) (net.Listener, error) {
+	// Remove a stale socket left by a previous server; other files are
+	// never replaced.
+	if fi, err := os.Lstat(addr); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, errors.Errorf("'%s' exists and is not a socket", addr)
+		}
+		if c, err := net.Dial("unix", addr); err == nil {
+			c.Close()
+			return nil, errors.Errorf("a server is already listening on '%s'", addr)
+		}
+		os.Remove(addr)
+	}
+
+	dir, err := ioutil.TempDir(filepath.Dir(addr), ".madonctl-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	tmpAddr := filepath.Join(dir, "sock")
+	ln, err := net.Listen("unix", tmpAddr)
+	if err != nil {
+		return nil, err
+	}
+	// The socket is removed by the server when it is closed
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err := os.Chmod(tmpAddr, 0600); err != nil {
+		ln.Close()
+		return nil, errors.Wrap(err, "cannot set the socket permissions")
+	}
+	if err := os.Rename(tmpAddr, addr); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+func (srv *streamServer) accept() {
+	for {
+		conn, err := srv.ln.Accept()
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				continue
+			}
+			return // The listener has been closed
+		}
+		go srv.handshake(conn)
+	}
+}
+
+// handshake checks the client token (if needed) and registers the client
+func (srv *streamServer) handshake(conn net.Conn) {
+	if srv.token != "" {
+		conn.SetReadDeadline(time.Now().Add(streamServerAuthTimeout))
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(line)), []byte(srv.token)) != 1 {
+			errPrint("Warning: stream server: client rejected (bad token)")
+			conn.Close()
+			return
+		}
+		conn.SetReadDeadline(time.Time{})
+	}
+
+	c := &streamServerClient{conn: conn, out: make(chan []byte, streamServerClientQueueSize)}
+	srv.mu.Lock()
+	srv.clients[c] = true
+	n := len(srv.clients)
+	srv.mu.Unlock()
+	if verbose {
+		errPrint("Stream server: new client (%d connected)", n)
+	}
+	srv.serve(c)
+}
+
+// serve writes the queued events to a client until it disconnects
+func (srv *streamServer) serve(c *streamServerClient) {
+	defer func() {
+		srv.remove(c)
+		c.conn.Close()
+	}()
+
+	// Detect disconnections (the clients are not expected to send
+	// anything)
+	gone := make(chan bool)
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			if _, err := c.conn.Read(buf); err != nil {
+				close(gone)
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-gone:
+			return
+		case line, ok := <-c.out:
+			if !ok {
+				return // Closed by the server
+			}
+			c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if _, err := c.conn.Write(line); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// remove unregisters a client; it returns false if the client had already
+// been removed.
+func (srv *streamServer) remove(c *streamServerClient) bool {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if !srv.clients[c] {
+		return false
+	}
+	delete(srv.clients, c)
+	close(c.out)
+	if verbose {
+		errPrint("Stream server: client disconnected (%d connected)", len(srv.clients))
+	}
+	return true
+}
+
+// broadcast sends an event to all the connected clients
+func (srv *streamServer) broadcast(ev mastodon.StreamEvent) error {
+	line, err := encodeStreamRecord(ev)
+	if err != nil || line == nil {
+		return err
+	}
+
+	var slow []*streamServerClient
+	srv.mu.Lock()
+	for c := range srv.clients {
+		select {
+		case c.out <- line:
+		default: // The client does not keep up
+			slow = append(slow, c)
+		}
+	}
+	srv.mu.Unlock()
+
+	for _, c := range slow {
+		if srv.remove(c) {
+			errPrint("Warning: stream server: disconnecting slow client")
+		}
+	}
+	return nil
+}
+
+// close stops the server and disconnects the clients
+func (srv *streamServer) close() {
+	srv.ln.Close()
+	srv.mu.Lock()
+	var clients []*streamServerClient
+	for c := range srv.clients {
+		clients = append(clients, c)
+	}
+	srv.mu.Unlock()
+	for _, c := range clients {
+		srv.remove(c)
+	}
+	if srv.network == "unix" {
+		os.Remove(srv.address)
+	}
+}
+
+// streamClientConnector returns a stream connector that reads the events
+// from a local stream server
+// The token is sent to the server when it is not empty.
+func streamClientConnector(address, token string) streamConnector {
+	return func(evChan chan<- mastodon.StreamEvent, stop chan bool) (chan bool, error) {
+		network, addr, err := parseStreamAddress(address)
+		if err != nil {
+			return nil, err
+		}
+		conn, err := net.Dial(network, addr)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot connect to the stream server")
+		}
+		if token != "" {
+			if _, err := fmt.Fprintf(conn, "%s\n", token); err != nil {
+				conn.Close()
+				return nil, errors.Wrap(err, "cannot send the token to the stream server")
+			}
+		}
+		if verbose {
+			errPrint("Connected to the stream server %s", address)
+		}
+
+		done := make(chan bool)
+		go readStreamRecords(conn, 0, evChan, stop, done)
+		return done, nil
+	}
+}
+
+func streamServeRunE(cmd *cobra.Command, args []string) error {
+	if streamOpts.listen == "" {
+		return errors.New("missing listen address (--listen)")
+	}
+	if streamOpts.connect != "" {
+		return errors.New("--connect cannot be used with the serve subcommand")
+	}
+	return streamRunE(cmd, args)
+}
+
+// streamServerToken returns the stream server token, from the command line
+// or the configuration (stream_server_token)
+func streamServerToken() string {
+	if streamOpts.serverToken != "" {
+		return streamOpts.serverToken
+	}
+	return viper.GetString("stream_server_token")
+}
@@ -41,8 +41,8 @@ func init() {
 	suggestionsGetSubcommand.Flags().UintVarP(&suggestionsOpts.keep, "keep", "k", 0, "Limit number of results")
 	//suggestionsGetSubcommand.Flags().BoolVar(&suggestionsOpts.all, "all", false, "Fetch all results")
 
-	suggestionsDeleteSubcommand.Flags().StringVarP(&suggestionsOpts.accountID, "account-id", "a", "", "Account ID number")
-	suggestionsDeleteSubcommand.Flags().StringVar(&suggestionsOpts.accountIDs, "account-ids", "", "Comma-separated list of account IDs")
+	suggestionsDeleteSubcommand.Flags().StringVarP(&suggestionsOpts.accountID, "account-id", "a", "", "Account ID, address or URL")
+	suggestionsDeleteSubcommand.Flags().StringVar(&suggestionsOpts.accountIDs, "account-ids", "", "Comma-separated list of account IDs, addresses or URLs")
 }
 
 var suggestionsSubcommands = []*cobra.Command{
@@ -126,15 +126,8 @@ func suggestionsDeleteRunE(cmd *cobra.Command, args []string) error {
 		return errors.New("incompatible options")
 	}
 
-	ids, err = splitIDs(opt.accountIDs)
-	if err != nil {
-		return errors.New("cannot parse account IDs")
-	}
 	if opt.accountID != "" { // Allow --account-id
-		ids = []madon.ActivityID{opt.accountID}
-	}
-	if len(ids) < 1 {
-		return errors.New("missing account IDs")
+		opt.accountIDs = opt.accountID
 	}
 
 	// We need to be logged in
@@ -142,9 +135,19 @@ func suggestionsDeleteRunE(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// The accounts can be given as IDs, addresses or URLs
+	ids, err = resolveAccountIDs(opt.accountIDs)
+	if err != nil {
+		errPrint("Error: %s", err.Error())
+		os.Exit(1)
+	}
+	if len(ids) < 1 {
+		return errors.New("missing account IDs")
+	}
+
 	for _, id := range ids {
-		if e := gClient.DeleteSuggestion(id); err != nil {
-			errPrint("Cannot remove account %d: %s", id, e)
+		if e := gClient.DeleteSuggestion(id); e != nil {
+			errPrint("Cannot remove account %s: %s", id, e)
 			err = e
 		}
 	}
@@ -79,16 +79,30 @@ func timelineRunE(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	sl, err := gClient.GetTimelines(tl, opt.local, opt.onlyMedia, limOpts)
+	lf, err := loadLocalFilters()
+	if err != nil {
+		return err
+	}
+
+	sl, err := gExtClient.GetTimelines(tl, opt.local, opt.onlyMedia, limOpts)
 	if err != nil {
 		errPrint("Error: %s", err.Error())
 		os.Exit(1)
 	}
 
+	sl = lf.filterStatuses(sl)
+	lf.report()
+
 	if opt.keep > 0 && len(sl) > int(opt.keep) {
 		sl = sl[:opt.keep]
 	}
 
+	// Only keep the server-side filters relevant to this timeline
+	filterContext := timelineFilterContext(tl)
+	for i := range sl {
+		sl[i].ApplyFilterContext(filterContext)
+	}
+
 	p, err := getPrinter()
 	if err != nil {
 		errPrint("Error: %s", err.Error())
@@ -96,3 +110,16 @@ func timelineRunE(cmd *cobra.Command, args []string) error {
 	}
 	return p.printObj(sl)
 }
+
+// timelineFilterContext returns the server-side filter context of a
+// timeline (or stream)
+func timelineFilterContext(tl string) string {
+	switch {
+	case tl == "home", tl == "user", strings.HasPrefix(tl, "!"):
+		return "home"
+	case tl == "public", tl == "local",
+		strings.HasPrefix(tl, ":"), strings.HasPrefix(tl, "#"):
+		return "public"
+	}
+	return "" // No filter context (e.g. direct messages)
+}
@@ -6,12 +6,15 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -62,19 +65,28 @@ type mcResourcePrinter interface {
 	setCommand(string)
 }
 
+// getColorMode returns the color mode from the color setting: "on", "off"
+// or "auto"
+func getColorMode() string {
+	switch viper.GetString("color") {
+	case "on", "true", "yes", "force":
+		return "on"
+	case "off", "false", "no":
+		return "off"
+	}
+	return "auto"
+}
+
 // getPrinter returns a resource printer for the requested output format.
 func getPrinter() (mcResourcePrinter, error) {
 	opt := make(printer.Options)
 	of := getOutputFormat()
 
 	// Initialize color mode
-	switch viper.GetString("color") {
-	case "on", "true", "yes", "force":
-		opt["color_mode"] = "on"
-	case "off", "false", "no":
-		opt["color_mode"] = "off"
-	default:
-		opt["color_mode"] = "auto"
+	opt["color_mode"] = getColorMode()
+
+	if viper.GetBool("show_filtered") {
+		opt["show_filtered"] = "true"
 	}
 
 	if of == "theme" {
@@ -145,6 +157,26 @@ func fileExists(filename string) bool {
 	return true
 }
 
+// parseDuration parses a duration string
+// In addition to the units accepted by time.ParseDuration, the "d" (day)
+// and "w" (week) suffixes can be used for integer values (e.g. "180d").
+func parseDuration(s string) (time.Duration, error) {
+	var unit time.Duration
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	default:
+		return time.ParseDuration(s)
+	}
+	n, err := strconv.ParseUint(s[:len(s)-1], 10, 32)
+	if err != nil {
+		return 0, errors.Errorf("invalid duration '%s'", s)
+	}
+	return time.Duration(n) * unit, nil
+}
+
 func errPrint(format string, a ...interface{}) (n int, err error) {
 	return fmt.Fprintf(os.Stderr, format+"\n", a...)
 }
@@ -154,18 +186,15 @@ func (mcp *mcPrinter) printObj(obj interface{}) error {
 		return mcp.PrintObj(obj, nil, "")
 	}
 
-	cmd := exec.Command(mcp.command)
-	stdin, err := cmd.StdinPipe()
-	if err != nil {
-		return err
-	}
-	err = mcp.PrintObj(obj, stdin, "")
-	stdin.Close()
-
-	if err != nil {
+	// Render the object first, so that the command can read its whole
+	// input even if it is larger than the pipe buffer.
+	var input bytes.Buffer
+	if err := mcp.PrintObj(obj, &input, ""); err != nil {
 		return err
 	}
 
+	cmd := exec.Command(mcp.command)
+	cmd.Stdin = &input
 	out, err := cmd.CombinedOutput()
 	if len(out) > 0 {
 		errPrint("Command output: %s", string(out))
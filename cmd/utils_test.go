@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+		err      bool
+	}{
+		{"90s", 90 * time.Second, false},
+		{"1h30m", 90 * time.Minute, false},
+		{"1d", 24 * time.Hour, false},
+		{"30d", 30 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"0d", 0, false},
+		{"d", 0, true},
+		{"1.5d", 0, true},
+		{"-1d", 0, true},
+		{"1y", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		d, err := parseDuration(tt.input)
+		if tt.err {
+			assert.NotNil(t, err, tt.input)
+			continue
+		}
+		assert.Nil(t, err, tt.input)
+		assert.Equal(t, tt.expected, d, tt.input)
+	}
+}
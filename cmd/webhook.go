@@ -0,0 +1,271 @@
+// Copyright © 2026 Mikael Berthe <mikael@lilotux.net>
+//
+// Licensed under the MIT license.
+// Please see the LICENSE file is this directory.
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Webhook delivery retry delays (the delay is doubled after each failure)
+const (
+	webhookRetryMinDelay = time.Second
+	webhookRetryMaxDelay = 5 * time.Minute
+)
+
+// Maximum time to wait for the queued events to be delivered when the
+// stream is terminated
+const webhookFlushTimeout = 5 * time.Second
+
+// webhookForwarder sends the stream events to a webhook endpoint.
+// The events are stored in an on-disk queue until they have been delivered,
+// so that they are not lost while the receiver is unavailable (or when
+// madonctl is restarted).
+// The queue directory is locked, so that the events are never sent twice
+// by concurrent forwarders.
+type webhookForwarder struct {
+	url             string
+	headers         http.Header
+	secret          []byte // HMAC key (optional)
+	signatureHeader string
+	queueDir        string
+	queueSize       int // Maximum number of queued events
+	queueLock       *fileLock
+	client          *http.Client
+
+	seq    int
+	notify chan bool // Wakes up the sender when an event is queued
+	quit   chan bool
+	done   chan bool
+}
+
+// newWebhookForwarder creates a webhook forwarder and starts its sender
+// goroutine.  The headers are given in the "Name: value" format.
+// The default queue directory depends on the webhook URL, so that the
+// queued events are only delivered to the endpoint they were queued for.
+func newWebhookForwarder(url string, headers []string, secret, signatureHeader, queueDir string, queueSize int) (*webhookForwarder, error) {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, errors.Errorf("invalid webhook URL '%s'", url)
+	}
+	if queueSize < 1 {
+		return nil, errors.New("the webhook queue size must be positive")
+	}
+
+	wf := &webhookForwarder{
+		url:             url,
+		headers:         make(http.Header),
+		signatureHeader: signatureHeader,
+		queueDir:        queueDir,
+		queueSize:       queueSize,
+		client:          &http.Client{Timeout: 30 * time.Second},
+		notify:          make(chan bool, 1),
+		quit:            make(chan bool),
+		done:            make(chan bool),
+	}
+	if secret != "" {
+		wf.secret = []byte(secret)
+	}
+
+	for _, h := range headers {
+		parts := strings.SplitN(h, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, errors.Errorf("invalid webhook header '%s'", h)
+		}
+		wf.headers.Add(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+	}
+
+	if wf.queueDir == "" {
+		cacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot determine the webhook queue directory")
+		}
+		sum := sha256.Sum256([]byte(url))
+		wf.queueDir = filepath.Join(cacheDir, AppName, "webhook-queue",
+			hex.EncodeToString(sum[:16]))
+	}
+	if err := os.MkdirAll(wf.queueDir, 0700); err != nil {
+		return nil, errors.Wrap(err, "cannot create the webhook queue directory")
+	}
+	lock, err := lockFile(filepath.Join(wf.queueDir, ".lock"))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot lock the webhook queue")
+	}
+	wf.queueLock = lock
+
+	go wf.sender()
+	wf.notify <- true // Send the events left from a previous run
+	return wf, nil
+}
+
+// queuedFiles returns the queued event files, oldest first
+func (wf *webhookForwarder) queuedFiles() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(wf.queueDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// enqueue stores an event in the queue
+// If the queue is full, the oldest events are dropped.
+func (wf *webhookForwarder) enqueue(obj interface{}) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return errors.Wrap(err, "cannot encode webhook event")
+	}
+
+	files, err := wf.queuedFiles()
+	if err != nil {
+		return errors.Wrap(err, "cannot read the webhook queue")
+	}
+	for len(files) >= wf.queueSize {
+		errPrint("Warning: webhook queue is full, dropping %s", filepath.Base(files[0]))
+		os.Remove(files[0])
+		files = files[1:]
+	}
+
+	// Write the event to a temporary file first, so that the sender never
+	// reads a partial event.
+	wf.seq++
+	name := fmt.Sprintf("%020d-%06d", time.Now().UnixNano(), wf.seq%1000000)
+	tmpName := filepath.Join(wf.queueDir, name+".tmp")
+	if err := ioutil.WriteFile(tmpName, data, 0600); err != nil {
+		return errors.Wrap(err, "cannot queue webhook event")
+	}
+	if err := os.Rename(tmpName, filepath.Join(wf.queueDir, name+".json")); err != nil {
+		return errors.Wrap(err, "cannot queue webhook event")
+	}
+
+	select {
+	case wf.notify <- true:
+	default: // The sender has already been notified
+	}
+	return nil
+}
+
+// sender delivers the queued events, in order
+func (wf *webhookForwarder) sender() {
+	defer close(wf.done)
+	delay := webhookRetryMinDelay
+
+	for {
+		select {
+		case <-wf.quit:
+			return
+		case <-wf.notify:
+		}
+
+		files, err := wf.queuedFiles()
+		if err != nil {
+			errPrint("Error: cannot read the webhook queue: %s", err.Error())
+			continue
+		}
+
+		for len(files) > 0 {
+			retry, err := wf.deliver(files[0])
+			if err == nil || !retry {
+				if err != nil {
+					errPrint("Error: webhook event dropped: %s", err.Error())
+				}
+				os.Remove(files[0])
+				files = files[1:]
+				delay = webhookRetryMinDelay
+				continue
+			}
+
+			if verbose {
+				errPrint("Webhook delivery failed (%s), retrying in %v", err.Error(), delay)
+			}
+			select {
+			case <-wf.quit:
+				return
+			case <-time.After(delay):
+			}
+			if delay *= 2; delay > webhookRetryMaxDelay {
+				delay = webhookRetryMaxDelay
+			}
+			// Reload the queue, events may have been dropped
+			if files, err = wf.queuedFiles(); err != nil {
+				errPrint("Error: cannot read the webhook queue: %s", err.Error())
+				break
+			}
+		}
+	}
+}
+
+// deliver posts a queued event to the webhook endpoint
+// It returns true if the delivery failed but should be retried.
+func (wf *webhookForwarder) deliver(file string) (bool, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil // Already dropped
+		}
+		return false, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, wf.url, bytes.NewReader(data))
+	if err != nil {
+		return false, err
+	}
+	for name, values := range wf.headers {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", AppName+"/"+VERSION)
+	if wf.secret != nil {
+		mac := hmac.New(sha256.New, wf.secret)
+		mac.Write(data)
+		req.Header.Set(wf.signatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	res, err := wf.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	io.Copy(ioutil.Discard, res.Body)
+	res.Body.Close()
+
+	switch {
+	case res.StatusCode >= 200 && res.StatusCode < 300:
+		return false, nil
+	case res.StatusCode == http.StatusRequestTimeout,
+		res.StatusCode == http.StatusTooManyRequests,
+		res.StatusCode >= 500:
+		return true, errors.Errorf("bad webhook status code (%d)", res.StatusCode)
+	}
+	// Other client errors are permanent
+	return false, errors.Errorf("bad webhook status code (%d)", res.StatusCode)
+}
+
+// close stops the sender; the undelivered events are kept in the queue.
+func (wf *webhookForwarder) close() {
+	// Give the sender a chance to deliver the last events
+	for deadline := time.Now().Add(webhookFlushTimeout); time.Now().Before(deadline); {
+		if files, err := wf.queuedFiles(); err != nil || len(files) == 0 {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	close(wf.quit)
+	<-wf.done
+	wf.queueLock.unlock()
+}
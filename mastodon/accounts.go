@@ -0,0 +1,225 @@
+// Copyright © 2026 Mikael Berthe <mikael@lilotux.net>
+//
+// Licensed under the MIT license.
+// Please see the LICENSE file is this directory.
+
+package mastodon
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/McKael/madon/v3"
+)
+
+// FollowParams contains the optional parameters of a follow request
+type FollowParams struct {
+	Reblogs   *bool    // Show the account boosts in the home timeline
+	Notify    *bool    // Notify when the account posts a status
+	Languages []string // Only show statuses in these languages (ISO 639-1)
+}
+
+// MuteParams contains the optional parameters of a mute request
+type MuteParams struct {
+	Notifications *bool         // Mute the notifications (defaults to true)
+	Duration      time.Duration // Duration of the mute (0 means indefinite)
+}
+
+// updateRelationship sends a relationship update request for an account
+// (op is follow, unfollow, block, unblock, mute, unmute, pin or unpin)
+func (c *Client) updateRelationship(op string, accountID madon.ActivityID, params url.Values) (*Relationship, error) {
+	if accountID == "" {
+		return nil, madon.ErrInvalidID
+	}
+
+	var rel Relationship
+	if err := c.apiCall("v1/accounts/"+accountID+"/"+op, http.MethodPost, params, nil, nil, &rel); err != nil {
+		return nil, err
+	}
+	if rel.ID == "" {
+		return nil, madon.ErrEntityNotFound
+	}
+	return &rel, nil
+}
+
+// FollowAccount follows an account (or updates the follow options if the
+// account is already followed)
+func (c *Client) FollowAccount(accountID madon.ActivityID, fp FollowParams) (*Relationship, error) {
+	params := make(url.Values)
+	if fp.Reblogs != nil {
+		params.Set("reblogs", strconv.FormatBool(*fp.Reblogs))
+	}
+	if fp.Notify != nil {
+		params.Set("notify", strconv.FormatBool(*fp.Notify))
+	}
+	for _, l := range fp.Languages {
+		params.Add("languages[]", l)
+	}
+	return c.updateRelationship("follow", accountID, params)
+}
+
+// UnfollowAccount unfollows an account
+func (c *Client) UnfollowAccount(accountID madon.ActivityID) (*Relationship, error) {
+	return c.updateRelationship("unfollow", accountID, nil)
+}
+
+// BlockAccount blocks an account
+func (c *Client) BlockAccount(accountID madon.ActivityID) (*Relationship, error) {
+	return c.updateRelationship("block", accountID, nil)
+}
+
+// UnblockAccount unblocks an account
+func (c *Client) UnblockAccount(accountID madon.ActivityID) (*Relationship, error) {
+	return c.updateRelationship("unblock", accountID, nil)
+}
+
+// MuteAccount mutes an account
+func (c *Client) MuteAccount(accountID madon.ActivityID, mp MuteParams) (*Relationship, error) {
+	params := make(url.Values)
+	if mp.Notifications != nil {
+		params.Set("notifications", strconv.FormatBool(*mp.Notifications))
+	}
+	if mp.Duration > 0 {
+		params.Set("duration", strconv.FormatInt(int64(mp.Duration/time.Second), 10))
+	}
+	return c.updateRelationship("mute", accountID, params)
+}
+
+// UnmuteAccount unmutes an account
+func (c *Client) UnmuteAccount(accountID madon.ActivityID) (*Relationship, error) {
+	return c.updateRelationship("unmute", accountID, nil)
+}
+
+// PinAccount endorses (pins) an account
+func (c *Client) PinAccount(accountID madon.ActivityID) (*Relationship, error) {
+	return c.updateRelationship("pin", accountID, nil)
+}
+
+// UnpinAccount cancels the endorsement of an account
+func (c *Client) UnpinAccount(accountID madon.ActivityID) (*Relationship, error) {
+	return c.updateRelationship("unpin", accountID, nil)
+}
+
+// LookupAccount returns the account with the given address (username for
+// local accounts, or user@domain)
+// Only the accounts already known by the instance can be found.
+func (c *Client) LookupAccount(acct string) (*madon.Account, error) {
+	if acct == "" {
+		return nil, madon.ErrInvalidID
+	}
+
+	params := make(url.Values)
+	params.Set("acct", acct)
+
+	var account madon.Account
+	if err := c.apiCall("v1/accounts/lookup", http.MethodGet, params, nil, nil, &account); err != nil {
+		return nil, err
+	}
+	if account.ID == "" {
+		return nil, madon.ErrEntityNotFound
+	}
+	return &account, nil
+}
+
+// SetAccountNote sets the private note about an account
+// An empty comment removes the note.
+func (c *Client) SetAccountNote(accountID madon.ActivityID, comment string) (*Relationship, error) {
+	params := make(url.Values)
+	params.Set("comment", comment)
+	return c.updateRelationship("note", accountID, params)
+}
+
+// Maximum number of accounts per relationships request
+const relationshipsBatchSize = 40
+
+// GetRelationships returns the relationships with the accounts
+// Large lists are split into several requests.
+func (c *Client) GetRelationships(accountIDs []madon.ActivityID) ([]Relationship, error) {
+	var rl []Relationship
+	for len(accountIDs) > 0 {
+		batch := accountIDs
+		if len(batch) > relationshipsBatchSize {
+			batch = batch[:relationshipsBatchSize]
+		}
+		accountIDs = accountIDs[len(batch):]
+
+		params := make(url.Values)
+		for _, id := range batch {
+			params.Add("id[]", id)
+		}
+		var page []Relationship
+		if err := c.apiCall("v1/accounts/relationships", http.MethodGet, params, nil, nil, &page); err != nil {
+			return nil, err
+		}
+		rl = append(rl, page...)
+	}
+	return rl, nil
+}
+
+// LastStatusTime returns the date of the last status of the account
+// The boolean is false if the date is unknown (e.g. if the account has never
+// posted).
+func (a *Account) LastStatusTime() (time.Time, bool) {
+	if a.LastStatusAt == nil || *a.LastStatusAt == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse("2006-01-02", *a.LastStatusAt)
+	if err != nil {
+		// Older servers return a full timestamp
+		if t, err = time.Parse(time.RFC3339, *a.LastStatusAt); err != nil {
+			return time.Time{}, false
+		}
+	}
+	return t, true
+}
+
+// GetAccountFollowers returns the list of accounts following an account
+// If lopt.All is true, several requests will be made until the API server
+// has nothing to return.
+func (c *Client) GetAccountFollowers(accountID madon.ActivityID, lopt *madon.LimitParams) ([]Account, error) {
+	if accountID == "" {
+		return nil, madon.ErrInvalidID
+	}
+	return c.getMultipleAccounts("accounts/"+accountID+"/followers", lopt)
+}
+
+// GetAccountFollowing returns the list of accounts followed by an account
+// If lopt.All is true, several requests will be made until the API server
+// has nothing to return.
+func (c *Client) GetAccountFollowing(accountID madon.ActivityID, lopt *madon.LimitParams) ([]Account, error) {
+	if accountID == "" {
+		return nil, madon.ErrInvalidID
+	}
+	return c.getMultipleAccounts("accounts/"+accountID+"/following", lopt)
+}
+
+// GetMutedAccounts returns the list of muted accounts
+// The MuteExpiresAt field is set for the temporary mutes.
+func (c *Client) GetMutedAccounts(lopt *madon.LimitParams) ([]Account, error) {
+	return c.getMultipleAccounts("mutes", lopt)
+}
+
+// getMultipleAccounts returns a list of account entities
+// If lopt.All is true, several requests will be made until the API server
+// has nothing to return.
+func (c *Client) getMultipleAccounts(endPoint string, lopt *madon.LimitParams) ([]Account, error) {
+	var accounts []Account
+	var links apiLinks
+	if err := c.apiCall("v1/"+endPoint, http.MethodGet, nil, lopt, &links, &accounts); err != nil {
+		return nil, err
+	}
+	if lopt != nil { // Fetch more pages to reach our limit
+		for (lopt.All || lopt.Limit > len(accounts)) && links.next != nil {
+			accountSlice := []Account{}
+			newlopt := links.next
+			links = apiLinks{}
+			if err := c.apiCall("v1/"+endPoint, http.MethodGet, nil, newlopt, &links, &accountSlice); err != nil {
+				return nil, err
+			}
+			accounts = append(accounts, accountSlice...)
+		}
+	}
+	return accounts, nil
+}
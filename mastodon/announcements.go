@@ -0,0 +1,60 @@
+// Copyright © 2026 Mikael Berthe <mikael@lilotux.net>
+//
+// Licensed under the MIT license.
+// Please see the LICENSE file is this directory.
+
+package mastodon
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/McKael/madon/v3"
+)
+
+// GetAnnouncements returns the list of active announcements
+// If withDismissed is true, the announcements that have been dismissed
+// by the user are returned as well.
+func (c *Client) GetAnnouncements(withDismissed bool) ([]Announcement, error) {
+	var params url.Values
+	if withDismissed {
+		params = url.Values{"with_dismissed": {"true"}}
+	}
+
+	var al []Announcement
+	if err := c.apiCall("v1/announcements", http.MethodGet, params, nil, nil, &al); err != nil {
+		return nil, err
+	}
+	return al, nil
+}
+
+// DismissAnnouncement marks an announcement as read
+func (c *Client) DismissAnnouncement(announcementID madon.ActivityID) error {
+	if announcementID == "" {
+		return madon.ErrInvalidID
+	}
+	endPoint := "v1/announcements/" + announcementID + "/dismiss"
+	return c.apiCall(endPoint, http.MethodPost, nil, nil, nil, nil)
+}
+
+// AddAnnouncementReaction adds an emoji reaction to an announcement
+// The emoji name is either a unicode emoji or a custom emoji shortcode.
+func (c *Client) AddAnnouncementReaction(announcementID madon.ActivityID, name string) error {
+	return c.updateAnnouncementReaction(announcementID, name, http.MethodPut)
+}
+
+// RemoveAnnouncementReaction removes an emoji reaction from an announcement
+func (c *Client) RemoveAnnouncementReaction(announcementID madon.ActivityID, name string) error {
+	return c.updateAnnouncementReaction(announcementID, name, http.MethodDelete)
+}
+
+func (c *Client) updateAnnouncementReaction(announcementID madon.ActivityID, name, method string) error {
+	if announcementID == "" {
+		return madon.ErrInvalidID
+	}
+	if name == "" {
+		return madon.ErrInvalidParameter
+	}
+	endPoint := "v1/announcements/" + announcementID + "/reactions/" + url.PathEscape(name)
+	return c.apiCall(endPoint, method, nil, nil, nil, nil)
+}
@@ -0,0 +1,214 @@
+// Copyright © 2026 Mikael Berthe <mikael@lilotux.net>
+//
+// Licensed under the MIT license.
+// Please see the LICENSE file is this directory.
+
+// Package mastodon implements the Mastodon API entities and calls that are
+// not (yet) available in the madon library.
+//
+// It reuses the madon client settings (instance, user token).
+package mastodon
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/McKael/madon/v3"
+)
+
+type apiLinks struct {
+	next, prev *madon.LimitParams
+}
+
+var linkRegex = regexp.MustCompile(`<([^>]+)>; rel="([^"]+)`)
+
+// parseLink extracts the pagination parameters from the Link headers
+func parseLink(links []string) (*apiLinks, error) {
+	if len(links) == 0 {
+		return nil, nil
+	}
+
+	al := new(apiLinks)
+	for _, l := range links {
+		for _, submatch := range linkRegex.FindAllStringSubmatch(l, -1) {
+			if len(submatch) != 3 {
+				continue
+			}
+			u, err := url.Parse(submatch[1])
+			if err != nil {
+				return al, err
+			}
+			q := u.Query()
+			since, max := q.Get("since_id"), q.Get("max_id")
+			if since == "" && max == "" {
+				continue
+			}
+			lp := &madon.LimitParams{SinceID: since, MaxID: max}
+			if lim := q.Get("limit"); lim != "" {
+				if lp.Limit, err = strconv.Atoi(lim); err != nil {
+					return al, err
+				}
+			}
+			switch submatch[2] {
+			case "prev":
+				al.prev = lp
+			case "next":
+				al.next = lp
+			}
+		}
+	}
+	return al, nil
+}
+
+// APIError is returned when the server replies with an error status code
+type APIError struct {
+	EndPoint   string
+	StatusCode int
+	Text       string // Error description
+	// RateLimitReset is the time when the rate limit will be reset (from
+	// the X-RateLimit-Reset header); it is zero if the header is missing.
+	RateLimitReset time.Time
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API query (%s) failed: bad server status code (%d): %s",
+		e.EndPoint, e.StatusCode, e.Text)
+}
+
+// Client wraps a madon client to provide extra API calls
+type Client struct {
+	mc *madon.Client
+
+	// Streaming connection health checks (see SetStreamHeartbeat)
+	streamPingInterval time.Duration
+	streamIdleTimeout  time.Duration
+}
+
+// NewClient returns a new Client using the madon client mc
+func NewClient(mc *madon.Client) *Client {
+	return &Client{mc: mc}
+}
+
+// apiCall makes a call to the Mastodon API server
+// The parameters are sent in the URL for GET and DELETE requests, and in the
+// request body for other methods.
+// If links is not nil, the prev/next links from the API response headers
+// will be set (if they exist) in the structure.
+func (c *Client) apiCall(endPoint, method string, params url.Values, lopt *madon.LimitParams, links *apiLinks, data interface{}) error {
+	if lopt != nil {
+		// Do not modify the caller's parameters
+		p := make(url.Values)
+		for k, v := range params {
+			p[k] = v
+		}
+		params = p
+		if lopt.Limit > 0 {
+			params.Set("limit", strconv.Itoa(lopt.Limit))
+		}
+		if lopt.SinceID != "" {
+			params.Set("since_id", lopt.SinceID)
+		}
+		if lopt.MaxID != "" {
+			params.Set("max_id", lopt.MaxID)
+		}
+	}
+
+	var body io.Reader
+	var contentType string
+	query := params.Encode()
+	switch method {
+	case http.MethodGet, http.MethodDelete:
+		if query != "" {
+			endPoint += "?" + query
+		}
+	default:
+		if query != "" {
+			body = bytes.NewBufferString(query)
+			contentType = "application/x-www-form-urlencoded"
+		}
+	}
+	return c.doRequest(endPoint, method, body, contentType, links, data)
+}
+
+// apiCallJSON makes a call to the Mastodon API server with a JSON-encoded
+// request body
+func (c *Client) apiCallJSON(endPoint, method string, obj interface{}, data interface{}) error {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return errors.Wrap(err, "cannot encode API request")
+	}
+	return c.doRequest(endPoint, method, bytes.NewBuffer(b), "application/json", nil, data)
+}
+
+func (c *Client) doRequest(endPoint, method string, body io.Reader, contentType string, links *apiLinks, data interface{}) error {
+	if c == nil || c.mc == nil {
+		return madon.ErrUninitializedClient
+	}
+
+	req, err := http.NewRequest(method, c.mc.APIBase+"/"+endPoint, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", fmt.Sprintf("madon/%s", madon.MadonVersion))
+	if c.mc.UserToken != nil {
+		req.Header.Set("Authorization", "Bearer "+c.mc.UserToken.AccessToken)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "API query (%s) failed", endPoint)
+	}
+	defer res.Body.Close()
+
+	resBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return errors.Wrapf(err, "API query (%s) failed", endPoint)
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		// Try to unmarshal the returned error object for a description
+		var mastodonError madon.Error
+		errorText := http.StatusText(res.StatusCode)
+		if json.Unmarshal(resBody, &mastodonError) == nil && mastodonError.Text != "" {
+			errorText = mastodonError.Text
+		}
+		apiErr := &APIError{EndPoint: endPoint, StatusCode: res.StatusCode, Text: errorText}
+		if reset := res.Header.Get("X-RateLimit-Reset"); reset != "" {
+			if t, err := time.Parse(time.RFC3339, reset); err == nil {
+				apiErr.RateLimitReset = t
+			}
+		}
+		return apiErr
+	}
+
+	if links != nil {
+		pLinks, err := parseLink(res.Header["Link"])
+		if err != nil {
+			return errors.Wrapf(err, "cannot decode header links (%s)", method)
+		}
+		if pLinks != nil {
+			*links = *pLinks
+		}
+	}
+
+	if data == nil || len(resBody) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resBody, data); err != nil {
+		return errors.Wrapf(err, "cannot decode API response (%s)", method)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+// Copyright © 2026 Mikael Berthe <mikael@lilotux.net>
+//
+// Licensed under the MIT license.
+// Please see the LICENSE file is this directory.
+
+package mastodon
+
+import (
+	"net/http"
+
+	"github.com/McKael/madon/v3"
+)
+
+// FilterParams contains the parameters used to create or update a filter
+// Nil fields are left unchanged when updating a filter.
+type FilterParams struct {
+	Title        *string
+	Context      []string
+	FilterAction *string
+	// ExpiresIn is the filter lifetime in seconds; 0 means no expiration
+	ExpiresIn *int64
+	Keywords  []FilterKeywordParams
+}
+
+// FilterKeywordParams describes a keyword to add, update or remove
+// Set Destroy (with the keyword ID) to remove a keyword from a filter.
+type FilterKeywordParams struct {
+	ID        madon.ActivityID `json:"id,omitempty"`
+	Keyword   string           `json:"keyword,omitempty"`
+	WholeWord bool             `json:"whole_word"`
+	Destroy   bool             `json:"_destroy,omitempty"`
+}
+
+// FilterContexts is the list of valid filter contexts
+var FilterContexts = []string{"home", "notifications", "public", "thread", "account"}
+
+// GetFilters returns the list of the user's filters
+func (c *Client) GetFilters() ([]Filter, error) {
+	var fl []Filter
+	if err := c.apiCall("v2/filters", http.MethodGet, nil, nil, nil, &fl); err != nil {
+		return nil, err
+	}
+	return fl, nil
+}
+
+// GetFilter returns a filter
+func (c *Client) GetFilter(filterID madon.ActivityID) (*Filter, error) {
+	if filterID == "" {
+		return nil, madon.ErrInvalidID
+	}
+	var f Filter
+	if err := c.apiCall("v2/filters/"+filterID, http.MethodGet, nil, nil, nil, &f); err != nil {
+		return nil, err
+	}
+	if f.ID == "" {
+		return nil, madon.ErrEntityNotFound
+	}
+	return &f, nil
+}
+
+// CreateFilter creates a new filter
+// The filter title and context are mandatory.
+func (c *Client) CreateFilter(params FilterParams) (*Filter, error) {
+	if params.Title == nil || *params.Title == "" || len(params.Context) == 0 {
+		return nil, madon.ErrInvalidParameter
+	}
+	return c.setFilter("", params)
+}
+
+// UpdateFilter updates an existing filter
+func (c *Client) UpdateFilter(filterID madon.ActivityID, params FilterParams) (*Filter, error) {
+	if filterID == "" {
+		return nil, madon.ErrInvalidID
+	}
+	return c.setFilter(filterID, params)
+}
+
+// DeleteFilter deletes a filter
+func (c *Client) DeleteFilter(filterID madon.ActivityID) error {
+	if filterID == "" {
+		return madon.ErrInvalidID
+	}
+	return c.apiCall("v2/filters/"+filterID, http.MethodDelete, nil, nil, nil, nil)
+}
+
+func (c *Client) setFilter(filterID madon.ActivityID, params FilterParams) (*Filter, error) {
+	endPoint := "v2/filters"
+	method := http.MethodPost
+	if filterID != "" {
+		endPoint += "/" + filterID
+		method = http.MethodPut
+	}
+
+	req := make(map[string]interface{})
+	if params.Title != nil {
+		req["title"] = *params.Title
+	}
+	if len(params.Context) > 0 {
+		req["context"] = params.Context
+	}
+	if params.FilterAction != nil {
+		req["filter_action"] = *params.FilterAction
+	}
+	if params.ExpiresIn != nil {
+		if *params.ExpiresIn > 0 {
+			req["expires_in"] = *params.ExpiresIn
+		} else {
+			req["expires_in"] = "" // Never expires
+		}
+	}
+	if len(params.Keywords) > 0 {
+		req["keywords_attributes"] = params.Keywords
+	}
+
+	var f Filter
+	if err := c.apiCallJSON(endPoint, method, req, &f); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
@@ -0,0 +1,35 @@
+// Copyright © 2026 Mikael Berthe <mikael@lilotux.net>
+//
+// Licensed under the MIT license.
+// Please see the LICENSE file is this directory.
+
+package mastodon
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/McKael/madon/v3"
+)
+
+// Search queries the server for accounts, statuses and hashtags
+// If resolve is true, the remote accounts and statuses are fetched by the
+// server.
+// Unlike the madon call, the errors are returned as *APIError (with the rate
+// limit reset time).
+func (c *Client) Search(query string, resolve bool) (*madon.Results, error) {
+	if query == "" {
+		return nil, madon.ErrInvalidParameter
+	}
+	params := make(url.Values)
+	params.Set("q", query)
+	if resolve {
+		params.Set("resolve", "true")
+	}
+
+	var results madon.Results
+	if err := c.apiCall("v2/search", http.MethodGet, params, nil, nil, &results); err != nil {
+		return nil, err
+	}
+	return &results, nil
+}
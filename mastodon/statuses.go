@@ -0,0 +1,141 @@
+// Copyright © 2026 Mikael Berthe <mikael@lilotux.net>
+//
+// Licensed under the MIT license.
+// Please see the LICENSE file is this directory.
+
+package mastodon
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/McKael/madon/v3"
+)
+
+// MadonStatus returns the status as a madon Status entity
+func (s *Status) MadonStatus() *madon.Status {
+	ms := s.Status
+	if s.Reblog != nil {
+		ms.Reblog = s.Reblog.MadonStatus()
+	}
+	return &ms
+}
+
+// ApplyFilterContext removes the filter results that do not apply to
+// the filter context ("home", "notifications", "public", "thread" or
+// "account").
+func (s *Status) ApplyFilterContext(context string) {
+	var fl []FilterResult
+	for _, fr := range s.Filtered {
+		for _, c := range fr.Filter.Context {
+			if c == context {
+				fl = append(fl, fr)
+				break
+			}
+		}
+	}
+	s.Filtered = fl
+	if s.Reblog != nil {
+		s.Reblog.ApplyFilterContext(context)
+	}
+}
+
+// FilterMatch returns the action and the title(s) of the filters matching
+// the status (or the reblogged status).
+// The action is "hide" or "warn", or an empty string if the status has not
+// been filtered.  The "hide" action has precedence.
+func (s *Status) FilterMatch() (action, title string) {
+	filtered := s.Filtered
+	if s.Reblog != nil {
+		filtered = append(filtered[:len(filtered):len(filtered)], s.Reblog.Filtered...)
+	}
+
+	var titles []string
+	for _, fr := range filtered {
+		switch fr.Filter.FilterAction {
+		case "hide":
+			return "hide", fr.Filter.Title
+		default: // Unknown actions are handled as "warn"
+			action = "warn"
+			titles = append(titles, fr.Filter.Title)
+		}
+	}
+	return action, strings.Join(titles, ", ")
+}
+
+// GetTimelines returns a timeline (a list of statuses)
+// timeline can be "home", "public", "direct", a hashtag (use ":hashtag" or
+// "#hashtag") or a list (use "!N", e.g. "!42" for list ID #42).
+// This is similar to the madon method, but the returned statuses
+// contain the server-side filter results.
+// For the public timelines, you can set 'local' to true to get only the
+// local instance.
+// Set 'onlyMedia' to true to only get statuses that have media attachments.
+// If lopt.All is true, several requests will be made until the API server
+// has nothing to return.
+// If lopt.Limit is set (and not All), several queries can be made until the
+// limit is reached.
+func (c *Client) GetTimelines(timeline string, local, onlyMedia bool, lopt *madon.LimitParams) ([]Status, error) {
+	var endPoint string
+
+	switch {
+	case timeline == "home", timeline == "public", timeline == "direct":
+		endPoint = "timelines/" + timeline
+	case strings.HasPrefix(timeline, ":"), strings.HasPrefix(timeline, "#"):
+		hashtag := timeline[1:]
+		if hashtag == "" {
+			return nil, errors.New("timelines API: empty hashtag")
+		}
+		endPoint = "timelines/tag/" + url.PathEscape(hashtag)
+	case len(timeline) > 1 && strings.HasPrefix(timeline, "!"):
+		// Check the timeline is a number
+		for _, n := range timeline[1:] {
+			if n < '0' || n > '9' {
+				return nil, errors.New("timelines API: invalid list ID")
+			}
+		}
+		endPoint = "timelines/list/" + timeline[1:]
+	default:
+		return nil, errors.New("GetTimelines: bad timelines argument")
+	}
+
+	params := make(url.Values)
+	if timeline == "public" && local {
+		params.Set("local", "true")
+	}
+	if onlyMedia {
+		params.Set("only_media", "true")
+	}
+
+	return c.getMultipleStatuses(endPoint, params, lopt)
+}
+
+// getMultipleStatuses returns a list of status entities
+// If lopt.All is true, several requests will be made until the API server
+// has nothing to return.
+func (c *Client) getMultipleStatuses(endPoint string, params url.Values, lopt *madon.LimitParams) ([]Status, error) {
+	var statuses []Status
+	var links apiLinks
+	if err := c.apiCall("v1/"+endPoint, http.MethodGet, params, lopt, &links, &statuses); err != nil {
+		return nil, err
+	}
+	if lopt != nil { // Fetch more pages to reach our limit
+		for (lopt.All || lopt.Limit > len(statuses)) && links.next != nil {
+			statusSlice := []Status{}
+			newlopt := *links.next
+			// The next links do not keep the lower bound
+			if lopt.SinceID != "" {
+				newlopt.SinceID = lopt.SinceID
+			}
+			links = apiLinks{}
+			if err := c.apiCall("v1/"+endPoint, http.MethodGet, params, &newlopt, &links, &statusSlice); err != nil {
+				return nil, err
+			}
+			statuses = append(statuses, statusSlice...)
+		}
+	}
+	return statuses, nil
+}
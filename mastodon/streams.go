@@ -0,0 +1,355 @@
+// Copyright © 2026 Mikael Berthe <mikael@lilotux.net>
+//
+// Licensed under the MIT license.
+// Please see the LICENSE file is this directory.
+
+package mastodon
+
+import (
+	"encoding/json"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+
+	"github.com/McKael/madon/v3"
+)
+
+// Stream identifies a channel of the streaming API
+type Stream struct {
+	Name  string // "user", "public", "public:local", "direct", "list", "hashtag"...
+	Param string // Hashtag (for "hashtag" streams) or list ID (for "list")
+}
+
+// StreamEvent contains a single event from the streaming API
+type StreamEvent struct {
+	Event      string      // Name of the event (error, update, notification, delete...)
+	Stream     Stream      // Originating stream (when provided by the server)
+	ReceivedAt time.Time   // Reception time
+	Data       interface{} // Status, Notification, Conversation, Announcement... or ID
+	Error      error       // Error message from the StreamListener
+	Raw        []byte      // Raw message from the server (if available)
+}
+
+// StreamConn is a connection to the streaming API.
+// Several streams can be multiplexed on a single connection.
+type StreamConn struct {
+	conn *websocket.Conn
+	mu   sync.Mutex // Protects websocket writes
+
+	pingInterval time.Duration
+	idleTimeout  time.Duration
+
+	hbMu          sync.Mutex // Protects lastHeartbeat
+	lastHeartbeat time.Time  // Last ping or pong received from the server
+}
+
+// SetStreamHeartbeat configures the health checks of the streaming
+// connections opened later.
+// If pingInterval is not zero, pings are sent periodically to the server.
+// If idleTimeout is not zero, a connection is considered dead (and closed
+// with an error event) when nothing has been received from the server
+// (message, ping or pong) during this delay.
+func (c *Client) SetStreamHeartbeat(pingInterval, idleTimeout time.Duration) {
+	c.streamPingInterval = pingInterval
+	c.streamIdleTimeout = idleTimeout
+}
+
+// LastHeartbeat returns the time of the last ping or pong received from the
+// server (zero if there has been none).
+func (sc *StreamConn) LastHeartbeat() time.Time {
+	sc.hbMu.Lock()
+	defer sc.hbMu.Unlock()
+	return sc.lastHeartbeat
+}
+
+// heartbeat records a ping or pong from the server
+func (sc *StreamConn) heartbeat() {
+	sc.hbMu.Lock()
+	sc.lastHeartbeat = time.Now()
+	sc.hbMu.Unlock()
+	sc.extendDeadline()
+}
+
+// extendDeadline postpones the idle timeout
+func (sc *StreamConn) extendDeadline() {
+	if sc.idleTimeout > 0 {
+		sc.conn.SetReadDeadline(time.Now().Add(sc.idleTimeout))
+	}
+}
+
+// pinger sends pings to the server until the done channel is closed
+func (sc *StreamConn) pinger(doneCh <-chan bool) {
+	ticker := time.NewTicker(sc.pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-doneCh:
+			return
+		case <-ticker.C:
+			// WriteControl can be called concurrently with other writes
+			sc.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second))
+		}
+	}
+}
+
+// checkStream validates a stream name and its parameter
+func checkStream(s Stream) error {
+	switch s.Name {
+	case "public", "public:media", "public:local", "public:local:media", "public:remote", "public:remote:media", "user", "user:notification", "direct":
+	case "hashtag", "hashtag:local", "list":
+		if s.Param == "" {
+			return madon.ErrInvalidParameter
+		}
+	default:
+		return madon.ErrInvalidParameter
+	}
+	return nil
+}
+
+// openStream opens a streaming API websocket, without any subscription
+func (c *Client) openStream() (*websocket.Conn, error) {
+	if !strings.HasPrefix(c.mc.APIBase, "http") {
+		return nil, errors.New("cannot create Websocket URL: unexpected API base URL")
+	}
+
+	// Build streaming websocket URL
+	u, err := url.Parse("ws" + c.mc.APIBase[4:] + "/v1/streaming/")
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create Websocket URL")
+	}
+
+	urlParams := url.Values{}
+	if c.mc.UserToken != nil {
+		urlParams.Add("access_token", c.mc.UserToken.AccessToken)
+	}
+	u.RawQuery = urlParams.Encode()
+
+	conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	return conn, err
+}
+
+// send writes a subscription request to the websocket
+func (sc *StreamConn) send(msgType string, s Stream) error {
+	if err := checkStream(s); err != nil {
+		return err
+	}
+
+	msg := map[string]string{"type": msgType, "stream": s.Name}
+	switch {
+	case strings.HasPrefix(s.Name, "hashtag"):
+		msg["tag"] = s.Param
+	case s.Name == "list":
+		msg["list"] = s.Param
+	}
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return sc.conn.WriteJSON(msg)
+}
+
+// Subscribe adds a stream to the connection
+func (sc *StreamConn) Subscribe(s Stream) error {
+	return sc.send("subscribe", s)
+}
+
+// Unsubscribe removes a stream from the connection
+func (sc *StreamConn) Unsubscribe(s Stream) error {
+	return sc.send("unsubscribe", s)
+}
+
+// close sends a close message to the server
+func (sc *StreamConn) close() error {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return sc.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+}
+
+// readStream reads from the websocket and sends events to the events channel
+// It stops when the connection is closed or when the stopCh channel is closed.
+// The goroutine will close the doneCh channel when it terminates.
+func (sc *StreamConn) readStream(events chan<- StreamEvent, stopCh <-chan bool, doneCh chan bool) {
+	defer sc.conn.Close()
+	defer close(doneCh)
+
+	sc.conn.SetPingHandler(func(data string) error {
+		sc.heartbeat()
+		err := sc.conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(10*time.Second))
+		if err == websocket.ErrCloseSent {
+			return nil
+		}
+		return err
+	})
+	sc.conn.SetPongHandler(func(string) error {
+		sc.heartbeat()
+		return nil
+	})
+	sc.extendDeadline()
+	if sc.pingInterval > 0 {
+		go sc.pinger(doneCh)
+	}
+
+	go func() {
+		select {
+		case <-stopCh:
+			// Close connection
+			sc.close()
+		case <-doneCh:
+			// Leave
+		}
+	}()
+
+	for {
+		_, data, err := sc.conn.ReadMessage()
+		if err != nil {
+			if strings.Contains(err.Error(), "close 1000 (normal)") {
+				break // Connection properly closed
+			}
+			e := errors.Wrap(err, "read error")
+			if ne, ok := err.(net.Error); ok && ne.Timeout() && sc.idleTimeout > 0 {
+				e = errors.Errorf("no activity for %v, the connection is considered dead", sc.idleTimeout)
+			}
+			events <- StreamEvent{Event: "error", ReceivedAt: time.Now(), Error: e}
+			break
+		}
+
+		sc.extendDeadline()
+
+		ev := DecodeStreamMessage(data)
+		ev.ReceivedAt = time.Now()
+
+		// Send event to the channel
+		events <- ev
+	}
+}
+
+// DecodeStreamMessage decodes a raw message from the streaming API.
+// If the message cannot be decoded, an "error" event is returned.
+// The raw message is kept in the Raw field of the returned event.
+func DecodeStreamMessage(data []byte) StreamEvent {
+	var msg struct {
+		Event   string
+		Stream  []string
+		Payload interface{}
+		Error   string
+	}
+
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return StreamEvent{Event: "error", Raw: data, Error: errors.Wrap(err, "could not decode message")}
+	}
+
+	if msg.Error != "" { // E.g. a subscription failure
+		return StreamEvent{Event: "error", Raw: data, Error: errors.New(msg.Error)}
+	}
+
+	var stream Stream
+	if len(msg.Stream) > 0 {
+		stream.Name = msg.Stream[0]
+		if len(msg.Stream) > 1 {
+			stream.Param = msg.Stream[1]
+		}
+	}
+
+	errorEvent := func(e error) StreamEvent {
+		return StreamEvent{Event: "error", Stream: stream, Raw: data, Error: e}
+	}
+
+	if msg.Event == "filters_changed" { // No payload
+		return StreamEvent{Event: msg.Event, Stream: stream, Raw: data}
+	}
+
+	strPayload, ok := msg.Payload.(string)
+	if !ok {
+		return errorEvent(errors.Errorf("could not decode %s event: payload isn't a string", msg.Event))
+	}
+
+	var obj interface{}
+
+	// Decode API object
+	switch msg.Event {
+	case "update", "status.update":
+		var s Status
+		if err := json.Unmarshal([]byte(strPayload), &s); err != nil {
+			return errorEvent(errors.Wrap(err, "could not decode status"))
+		}
+		obj = s
+	case "notification":
+		var notif madon.Notification
+		if err := json.Unmarshal([]byte(strPayload), &notif); err != nil {
+			return errorEvent(errors.Wrap(err, "could not decode notification"))
+		}
+		obj = notif
+	case "conversation":
+		var conv Conversation
+		if err := json.Unmarshal([]byte(strPayload), &conv); err != nil {
+			return errorEvent(errors.Wrap(err, "could not decode conversation"))
+		}
+		obj = conv
+	case "announcement":
+		var a Announcement
+		if err := json.Unmarshal([]byte(strPayload), &a); err != nil {
+			return errorEvent(errors.Wrap(err, "could not decode announcement"))
+		}
+		obj = a
+	case "announcement.reaction":
+		var r AnnouncementReactionEvent
+		if err := json.Unmarshal([]byte(strPayload), &r); err != nil {
+			return errorEvent(errors.Wrap(err, "could not decode announcement reaction"))
+		}
+		obj = r
+	case "delete", "announcement.delete":
+		obj = strPayload // statusID or announcementID
+	default:
+		return errorEvent(errors.Errorf("unhandled event '%s'", msg.Event))
+	}
+
+	return StreamEvent{Event: msg.Event, Stream: stream, Raw: data, Data: obj}
+}
+
+// StreamListener listens to one or several streams from the Mastodon server,
+// using a single websocket connection.
+// The stream names can be "user", "public", "public:local", "direct",
+// "list" or "hashtag" (see the Stream type).
+// For 'hashtag' and 'list', the Param field cannot be empty.
+// The events are sent to the events channel (the errors as well); they are
+// tagged with their originating stream.
+// The streaming is terminated if the 'stopCh' channel is closed.
+// The 'doneCh' channel is closed if the connection is closed by the server.
+// The returned StreamConn can be used to subscribe to other streams or to
+// unsubscribe.
+// Please note that this method launches a goroutine to listen to the events.
+func (c *Client) StreamListener(streams []Stream, events chan<- StreamEvent, stopCh <-chan bool, doneCh chan bool) (*StreamConn, error) {
+	if c == nil || c.mc == nil {
+		return nil, madon.ErrUninitializedClient
+	}
+
+	for _, s := range streams {
+		if err := checkStream(s); err != nil {
+			return nil, err
+		}
+	}
+
+	conn, err := c.openStream()
+	if err != nil {
+		return nil, err
+	}
+
+	sc := &StreamConn{
+		conn:         conn,
+		pingInterval: c.streamPingInterval,
+		idleTimeout:  c.streamIdleTimeout,
+	}
+	for _, s := range streams {
+		if err := sc.Subscribe(s); err != nil {
+			conn.Close()
+			return nil, errors.Wrap(err, "subscription failed")
+		}
+	}
+
+	go sc.readStream(events, stopCh, doneCh)
+	return sc, nil
+}
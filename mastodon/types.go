@@ -0,0 +1,137 @@
+// Copyright © 2026 Mikael Berthe <mikael@lilotux.net>
+//
+// Licensed under the MIT license.
+// Please see the LICENSE file is this directory.
+
+package mastodon
+
+import (
+	"time"
+
+	"github.com/McKael/madon/v3"
+)
+
+// Account represents a Mastodon account entity
+// It extends the madon Account entity with the fields not supported by the
+// madon library.
+type Account struct {
+	madon.Account
+	LastStatusAt  *string    `json:"last_status_at"`            // Date (YYYY-MM-DD)
+	MuteExpiresAt *time.Time `json:"mute_expires_at,omitempty"` // For muted accounts
+}
+
+// AccountWithNote is an account with the private note the user has set
+// on it (see the relationship Note field)
+type AccountWithNote struct {
+	madon.Account
+	PrivateNote string `json:"private_note,omitempty"`
+}
+
+// Announcement represents a Mastodon announcement entity
+type Announcement struct {
+	ID          madon.ActivityID       `json:"id"`
+	Content     string                 `json:"content"`
+	StartsAt    *time.Time             `json:"starts_at"`
+	EndsAt      *time.Time             `json:"ends_at"`
+	AllDay      bool                   `json:"all_day"`
+	PublishedAt time.Time              `json:"published_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+	Read        bool                   `json:"read"`
+	Mentions    []madon.Mention        `json:"mentions"`
+	Statuses    []AnnouncementStatus   `json:"statuses"`
+	Tags        []madon.Tag            `json:"tags"`
+	Emojis      []madon.Emoji          `json:"emojis"`
+	Reactions   []AnnouncementReaction `json:"reactions"`
+}
+
+// AnnouncementStatus is a status linked in an announcement
+type AnnouncementStatus struct {
+	ID  madon.ActivityID `json:"id"`
+	URL string           `json:"url"`
+}
+
+// AnnouncementReaction represents an emoji reaction to an announcement
+type AnnouncementReaction struct {
+	Name      string `json:"name"`
+	Count     int64  `json:"count"`
+	Me        bool   `json:"me"`
+	URL       string `json:"url,omitempty"`
+	StaticURL string `json:"static_url,omitempty"`
+}
+
+// AnnouncementReactionEvent is the payload of an "announcement.reaction"
+// streaming event
+type AnnouncementReactionEvent struct {
+	Name           string           `json:"name"`
+	Count          int64            `json:"count"`
+	AnnouncementID madon.ActivityID `json:"announcement_id"`
+}
+
+// Conversation represents a Mastodon conversation entity
+type Conversation struct {
+	ID         madon.ActivityID `json:"id"`
+	Unread     bool             `json:"unread"`
+	Accounts   []madon.Account  `json:"accounts"`
+	LastStatus *Status          `json:"last_status"`
+}
+
+// Filter represents a Mastodon (v2) filter entity
+type Filter struct {
+	ID           madon.ActivityID `json:"id"`
+	Title        string           `json:"title"`
+	Context      []string         `json:"context"`
+	ExpiresAt    *time.Time       `json:"expires_at"`
+	FilterAction string           `json:"filter_action"`
+	Keywords     []FilterKeyword  `json:"keywords"`
+	Statuses     []FilterStatus   `json:"statuses"`
+}
+
+// FilterKeyword represents a keyword of a Mastodon filter
+type FilterKeyword struct {
+	ID        madon.ActivityID `json:"id"`
+	Keyword   string           `json:"keyword"`
+	WholeWord bool             `json:"whole_word"`
+}
+
+// FilterStatus represents a status filtered by a Mastodon filter
+type FilterStatus struct {
+	ID       madon.ActivityID `json:"id"`
+	StatusID madon.ActivityID `json:"status_id"`
+}
+
+// FilterResult represents a filter match on a status
+type FilterResult struct {
+	Filter         Filter             `json:"filter"`
+	KeywordMatches []string           `json:"keyword_matches"`
+	StatusMatches  []madon.ActivityID `json:"status_matches"`
+}
+
+// Relationship represents a Mastodon relationship entity
+// It replaces the madon Relationship entity, which lacks several fields.
+type Relationship struct {
+	ID                  madon.ActivityID `json:"id"`
+	Following           bool             `json:"following"`
+	ShowingReblogs      bool             `json:"showing_reblogs"`
+	Notifying           bool             `json:"notifying"`
+	Languages           []string         `json:"languages"`
+	FollowedBy          bool             `json:"followed_by"`
+	Blocking            bool             `json:"blocking"`
+	BlockedBy           bool             `json:"blocked_by"`
+	Muting              bool             `json:"muting"`
+	MutingNotifications bool             `json:"muting_notifications"`
+	Requested           bool             `json:"requested"`
+	RequestedBy         bool             `json:"requested_by"`
+	DomainBlocking      bool             `json:"domain_blocking"`
+	Endorsed            bool             `json:"endorsed"`
+	Note                string           `json:"note"`
+}
+
+// Status represents a Mastodon status entity
+// It extends the madon Status entity with the fields not supported by the
+// madon library.
+type Status struct {
+	madon.Status
+	Reblog   *Status        `json:"reblog"`
+	EditedAt *time.Time     `json:"edited_at,omitempty"`
+	Filtered []FilterResult `json:"filtered,omitempty"`
+}
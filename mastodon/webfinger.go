@@ -0,0 +1,97 @@
+// Copyright © 2026 Mikael Berthe <mikael@lilotux.net>
+//
+// Licensed under the MIT license.
+// Please see the LICENSE file is this directory.
+
+package mastodon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/McKael/madon/v3"
+)
+
+// WebFingerLink is a link of a WebFinger resource
+type WebFingerLink struct {
+	Rel  string `json:"rel"`
+	Type string `json:"type"`
+	Href string `json:"href"`
+}
+
+// WebFingerResource is a WebFinger resource descriptor (RFC 7033)
+type WebFingerResource struct {
+	Subject string          `json:"subject"`
+	Aliases []string        `json:"aliases"`
+	Links   []WebFingerLink `json:"links"`
+}
+
+// ActorURL returns the ActivityPub actor URL of the resource, or the profile
+// page URL if there is no actor link
+func (r *WebFingerResource) ActorURL() string {
+	var profile string
+	for _, l := range r.Links {
+		switch {
+		case l.Rel == "self" && strings.Contains(l.Type, "activity+json"):
+			return l.Href
+		case l.Rel == "self" && strings.Contains(l.Type, "activitystreams"):
+			return l.Href
+		case l.Rel == "http://webfinger.net/rel/profile-page" && profile == "":
+			profile = l.Href
+		}
+	}
+	return profile
+}
+
+var webFingerClient = &http.Client{Timeout: 15 * time.Second}
+
+// WebFinger queries the server of the domain of the address (user@domain)
+// to get the WebFinger resource of the account
+func WebFinger(address string) (*WebFingerResource, error) {
+	address = strings.TrimPrefix(address, "@")
+	i := strings.LastIndex(address, "@")
+	if i < 1 || i == len(address)-1 {
+		return nil, errors.Errorf("invalid account address '%s'", address)
+	}
+	domain := address[i+1:]
+
+	u := url.URL{
+		Scheme:   "https",
+		Host:     domain,
+		Path:     "/.well-known/webfinger",
+		RawQuery: url.Values{"resource": {"acct:" + address}}.Encode(),
+	}
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/jrd+json, application/json")
+	req.Header.Set("User-Agent", fmt.Sprintf("madon/%s", madon.MadonVersion))
+
+	res, err := webFingerClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "WebFinger query failed")
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("WebFinger query failed: bad server status code (%d)", res.StatusCode)
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "WebFinger query failed")
+	}
+
+	var r WebFingerResource
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil, errors.Wrap(err, "cannot decode WebFinger resource")
+	}
+	return &r, nil
+}
@@ -0,0 +1,29 @@
+package mastodon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebFingerActorURL(t *testing.T) {
+	profile := WebFingerLink{Rel: "http://webfinger.net/rel/profile-page", Type: "text/html", Href: "https://example.com/@alice"}
+	actor := WebFingerLink{Rel: "self", Type: "application/activity+json", Href: "https://example.com/users/alice"}
+	ldActor := WebFingerLink{Rel: "self", Type: `application/ld+json; profile="https://www.w3.org/ns/activitystreams"`, Href: "https://example.org/actor/bob"}
+	other := WebFingerLink{Rel: "self", Type: "text/html", Href: "https://example.com/self"}
+
+	tests := []struct {
+		name     string
+		links    []WebFingerLink
+		expected string
+	}{
+		{"actor", []WebFingerLink{profile, actor}, actor.Href},
+		{"ld+json actor", []WebFingerLink{ldActor, profile}, ldActor.Href},
+		{"profile page", []WebFingerLink{other, profile}, profile.Href},
+		{"no link", nil, ""},
+	}
+	for _, tt := range tests {
+		r := WebFingerResource{Subject: "acct:alice@example.com", Links: tt.links}
+		assert.Equal(t, tt.expected, r.ActorURL(), tt.name)
+	}
+}
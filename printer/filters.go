@@ -0,0 +1,42 @@
+// Copyright © 2026 Mikael Berthe <mikael@lilotux.net>
+//
+// Licensed under the MIT license.
+// Please see the LICENSE file is this directory.
+
+package printer
+
+import (
+	"html"
+
+	"github.com/McKael/madonctl/v3/mastodon"
+)
+
+// applyStatusFilters applies the server-side filter results to a status
+// It returns nil if the status should be hidden.  If the status should be
+// displayed with a warning, a copy of the status is returned with its
+// contents replaced by the filter title.
+func applyStatusFilters(s *mastodon.Status, showFiltered bool) *mastodon.Status {
+	if s == nil || showFiltered {
+		return s
+	}
+
+	action, title := s.FilterMatch()
+	switch action {
+	case "":
+		return s
+	case "hide":
+		return nil
+	}
+
+	ns := *s
+	target := &ns
+	if s.Reblog != nil {
+		r := *s.Reblog
+		ns.Reblog = &r
+		target = &r
+	}
+	target.Content = html.EscapeString("Filtered: " + title)
+	target.SpoilerText = ""
+	target.MediaAttachments = nil
+	return &ns
+}
@@ -10,26 +10,34 @@ import (
 	"io"
 	"os"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/McKael/madon/v3"
+	"github.com/McKael/madonctl/v3/mastodon"
 	"github.com/McKael/madonctl/v3/printer/html2text"
 )
 
 // PlainPrinter is the default "plain text" printer
 type PlainPrinter struct {
-	Indent      string
-	NoSubtitles bool
+	Indent       string
+	NoSubtitles  bool
+	ShowFiltered bool // Ignore server-side filters
 }
 
 // NewPrinterPlain returns a plaintext ResourcePrinter
 // For PlainPrinter, the option parameter contains the indent prefix.
+// If the "show_filtered" option is "true", the statuses matching server-side
+// filters are displayed as usual.
 func NewPrinterPlain(options Options) (*PlainPrinter, error) {
 	indentInc := "  "
 	if i, ok := options["indent"]; ok {
 		indentInc = i
 	}
-	return &PlainPrinter{Indent: indentInc}, nil
+	return &PlainPrinter{
+		Indent:       indentInc,
+		ShowFiltered: options["show_filtered"] == "true",
+	}, nil
 }
 
 // PrintObj sends the object as text to the writer
@@ -45,12 +53,32 @@ func (p *PlainPrinter) PrintObj(obj interface{}, w io.Writer, initialIndent stri
 		[]madon.List, []madon.Mention, []madon.Notification,
 		[]madon.Relationship, []madon.Report, []madon.Results,
 		[]madon.Status, []madon.StreamEvent, []madon.Tag,
-		[]madon.WeekActivity, []madon.DomainName:
+		[]madon.WeekActivity, []madon.DomainName,
+		[]mastodon.Account, []mastodon.Announcement,
+		[]mastodon.AnnouncementReactionEvent,
+		[]mastodon.Conversation, []mastodon.Filter,
+		[]mastodon.Relationship, []mastodon.Status:
 		return p.plainForeach(o, w, initialIndent)
 	case *madon.DomainName:
 		return p.plainPrintDomainName(o, w, initialIndent)
 	case madon.DomainName:
 		return p.plainPrintDomainName(&o, w, initialIndent)
+	case *mastodon.Announcement:
+		return p.plainPrintAnnouncement(o, w, initialIndent)
+	case mastodon.Announcement:
+		return p.plainPrintAnnouncement(&o, w, initialIndent)
+	case *mastodon.AnnouncementReactionEvent:
+		return p.plainPrintAnnouncementReactionEvent(o, w, initialIndent)
+	case mastodon.AnnouncementReactionEvent:
+		return p.plainPrintAnnouncementReactionEvent(&o, w, initialIndent)
+	case *mastodon.Account:
+		return p.plainPrintExtAccount(o, w, initialIndent)
+	case mastodon.Account:
+		return p.plainPrintExtAccount(&o, w, initialIndent)
+	case *mastodon.AccountWithNote:
+		return p.plainPrintAccountWithNote(o, w, initialIndent)
+	case mastodon.AccountWithNote:
+		return p.plainPrintAccountWithNote(&o, w, initialIndent)
 	case *madon.Account:
 		return p.plainPrintAccount(o, w, initialIndent)
 	case madon.Account:
@@ -67,10 +95,18 @@ func (p *PlainPrinter) PrintObj(obj interface{}, w io.Writer, initialIndent stri
 		return p.plainPrintContext(o, w, initialIndent)
 	case madon.Context:
 		return p.plainPrintContext(&o, w, initialIndent)
+	case *mastodon.Conversation:
+		return p.plainPrintConversation(o, w, initialIndent)
+	case mastodon.Conversation:
+		return p.plainPrintConversation(&o, w, initialIndent)
 	case *madon.Emoji:
 		return p.plainPrintEmoji(o, w, initialIndent)
 	case madon.Emoji:
 		return p.plainPrintEmoji(&o, w, initialIndent)
+	case *mastodon.Filter:
+		return p.plainPrintFilter(o, w, initialIndent)
+	case mastodon.Filter:
+		return p.plainPrintFilter(&o, w, initialIndent)
 	case *madon.Instance:
 		return p.plainPrintInstance(o, w, initialIndent)
 	case madon.Instance:
@@ -91,6 +127,10 @@ func (p *PlainPrinter) PrintObj(obj interface{}, w io.Writer, initialIndent stri
 		return p.plainPrintRelationship(o, w, initialIndent)
 	case madon.Relationship:
 		return p.plainPrintRelationship(&o, w, initialIndent)
+	case *mastodon.Relationship:
+		return p.plainPrintExtRelationship(o, w, initialIndent)
+	case mastodon.Relationship:
+		return p.plainPrintExtRelationship(&o, w, initialIndent)
 	case *madon.Report:
 		return p.plainPrintReport(o, w, initialIndent)
 	case madon.Report:
@@ -99,6 +139,10 @@ func (p *PlainPrinter) PrintObj(obj interface{}, w io.Writer, initialIndent stri
 		return p.plainPrintResults(o, w, initialIndent)
 	case madon.Results:
 		return p.plainPrintResults(&o, w, initialIndent)
+	case *mastodon.Status:
+		return p.plainPrintFilteredStatus(o, w, initialIndent)
+	case mastodon.Status:
+		return p.plainPrintFilteredStatus(&o, w, initialIndent)
 	case *madon.Status:
 		return p.plainPrintStatus(o, w, initialIndent)
 	case madon.Status:
@@ -217,6 +261,59 @@ func (p *PlainPrinter) plainPrintAccount(a *madon.Account, w io.Writer, indent s
 	return nil
 }
 
+func (p *PlainPrinter) plainPrintExtAccount(a *mastodon.Account, w io.Writer, indent string) error {
+	if err := p.plainPrintAccount(&a.Account, w, indent); err != nil {
+		return err
+	}
+	if a.LastStatusAt != nil {
+		indentedPrint(w, indent, false, true, "Last status", "%s", *a.LastStatusAt)
+	}
+	if a.MuteExpiresAt != nil {
+		indentedPrint(w, indent, false, false, "Mute expires", "%v", a.MuteExpiresAt.Local())
+	}
+	return nil
+}
+
+func (p *PlainPrinter) plainPrintAccountWithNote(a *mastodon.AccountWithNote, w io.Writer, indent string) error {
+	if err := p.plainPrintAccount(&a.Account, w, indent); err != nil {
+		return err
+	}
+	indentedPrint(w, indent, false, true, "Private note", "%s", a.PrivateNote)
+	return nil
+}
+
+func (p *PlainPrinter) plainPrintAnnouncement(a *mastodon.Announcement, w io.Writer, indent string) error {
+	indentedPrint(w, indent, true, false, "Announcement ID", "%s", a.ID)
+	indentedPrint(w, indent, false, false, "Published", "%v", a.PublishedAt.Local())
+	if !a.UpdatedAt.IsZero() && !a.UpdatedAt.Equal(a.PublishedAt) {
+		indentedPrint(w, indent, false, false, "Updated", "%v", a.UpdatedAt.Local())
+	}
+	if a.StartsAt != nil {
+		indentedPrint(w, indent, false, false, "Starts", "%v", a.StartsAt.Local())
+	}
+	if a.EndsAt != nil {
+		indentedPrint(w, indent, false, false, "Ends", "%v", a.EndsAt.Local())
+	}
+	if a.Read {
+		indentedPrint(w, indent, false, false, "Read", "%v", a.Read)
+	}
+	indentedPrint(w, indent, false, false, "Contents", "%s", html2string(a.Content))
+	for _, r := range a.Reactions {
+		me := ""
+		if r.Me {
+			me = " (including you)"
+		}
+		indentedPrint(w, indent+p.Indent, true, false, "Reaction", "%s × %d%s", r.Name, r.Count, me)
+	}
+	return nil
+}
+
+func (p *PlainPrinter) plainPrintAnnouncementReactionEvent(r *mastodon.AnnouncementReactionEvent, w io.Writer, indent string) error {
+	indentedPrint(w, indent, true, false, "Announcement ID", "%s", r.AnnouncementID)
+	indentedPrint(w, indent, false, false, "Reaction", "%s × %d", r.Name, r.Count)
+	return nil
+}
+
 func (p *PlainPrinter) plainPrintAttachment(a *madon.Attachment, w io.Writer, indent string) error {
 	indentedPrint(w, indent, true, false, "Attachment ID", "%s", a.ID)
 	indentedPrint(w, indent, false, false, "Type", "%s", a.Type)
@@ -255,12 +352,48 @@ func (p *PlainPrinter) plainPrintContext(c *madon.Context, w io.Writer, indent s
 	return nil
 }
 
+func (p *PlainPrinter) plainPrintConversation(c *mastodon.Conversation, w io.Writer, indent string) error {
+	indentedPrint(w, indent, true, false, "Conversation ID", "%s", c.ID)
+	if c.Unread {
+		indentedPrint(w, indent, false, false, "Unread", "%v", c.Unread)
+	}
+	for _, a := range c.Accounts {
+		indentedPrint(w, indent+p.Indent, true, false, "Account", "(%s) @%s - %s",
+			a.ID, a.Acct, a.DisplayName)
+	}
+	if c.LastStatus != nil {
+		p.plainPrintFilteredStatus(c.LastStatus, w, indent+p.Indent)
+	}
+	return nil
+}
+
 func (p *PlainPrinter) plainPrintEmoji(e *madon.Emoji, w io.Writer, indent string) error {
 	indentedPrint(w, indent, true, false, "Emoji shortcode", "%s", e.ShortCode)
 	indentedPrint(w, indent, false, false, "URL", "%s", e.URL)
 	return nil
 }
 
+func (p *PlainPrinter) plainPrintFilter(f *mastodon.Filter, w io.Writer, indent string) error {
+	indentedPrint(w, indent, true, false, "Filter ID", "%s", f.ID)
+	indentedPrint(w, indent, false, false, "Title", "%s", f.Title)
+	indentedPrint(w, indent, false, false, "Context", "%s", strings.Join(f.Context, ", "))
+	indentedPrint(w, indent, false, false, "Action", "%s", f.FilterAction)
+	if f.ExpiresAt != nil {
+		indentedPrint(w, indent, false, false, "Expires", "%v", f.ExpiresAt.Local())
+	}
+	for _, k := range f.Keywords {
+		ww := ""
+		if k.WholeWord {
+			ww = " (whole word)"
+		}
+		indentedPrint(w, indent+p.Indent, true, false, "Keyword", "%s%s", k.Keyword, ww)
+	}
+	for _, s := range f.Statuses {
+		indentedPrint(w, indent+p.Indent, true, false, "Status ID", "%s", s.StatusID)
+	}
+	return nil
+}
+
 func (p *PlainPrinter) plainPrintInstance(i *madon.Instance, w io.Writer, indent string) error {
 	indentedPrint(w, indent, true, false, "Instance title", "%s", i.Title)
 	indentedPrint(w, indent, false, true, "Description", "%s", html2string(i.Description))
@@ -314,6 +447,27 @@ func (p *PlainPrinter) plainPrintRelationship(r *madon.Relationship, w io.Writer
 	return nil
 }
 
+func (p *PlainPrinter) plainPrintExtRelationship(r *mastodon.Relationship, w io.Writer, indent string) error {
+	indentedPrint(w, indent, true, false, "Account ID", "%s", r.ID)
+	indentedPrint(w, indent, false, false, "Following", "%v", r.Following)
+	if r.Following {
+		indentedPrint(w, indent, false, false, "Showing reblogs", "%v", r.ShowingReblogs)
+		indentedPrint(w, indent, false, false, "Notifying", "%v", r.Notifying)
+		indentedPrint(w, indent, false, true, "Languages", "%s", strings.Join(r.Languages, ", "))
+	}
+	indentedPrint(w, indent, false, false, "Followed-by", "%v", r.FollowedBy)
+	indentedPrint(w, indent, false, false, "Blocking", "%v", r.Blocking)
+	indentedPrint(w, indent, false, false, "Blocked-by", "%v", r.BlockedBy)
+	indentedPrint(w, indent, false, false, "Domain blocking", "%v", r.DomainBlocking)
+	indentedPrint(w, indent, false, false, "Muting", "%v", r.Muting)
+	indentedPrint(w, indent, false, false, "Muting notifications", "%v", r.MutingNotifications)
+	indentedPrint(w, indent, false, false, "Endorsed", "%v", r.Endorsed)
+	indentedPrint(w, indent, false, false, "Requested", "%v", r.Requested)
+	indentedPrint(w, indent, false, false, "Requested-by", "%v", r.RequestedBy)
+	indentedPrint(w, indent, false, true, "Note", "%s", r.Note)
+	return nil
+}
+
 func (p *PlainPrinter) plainPrintReport(r *madon.Report, w io.Writer, indent string) error {
 	indentedPrint(w, indent, true, false, "Report ID", "%s", r.ID)
 	indentedPrint(w, indent, false, false, "Action taken", "%s", r.ActionTaken)
@@ -395,6 +549,19 @@ func (p *PlainPrinter) plainPrintStatus(s *madon.Status, w io.Writer, indent str
 	return nil
 }
 
+func (p *PlainPrinter) plainPrintFilteredStatus(s *mastodon.Status, w io.Writer, indent string) error {
+	if s = applyStatusFilters(s, p.ShowFiltered); s == nil {
+		return nil // Hidden by a server-side filter
+	}
+	if err := p.plainPrintStatus(s.MadonStatus(), w, indent); err != nil {
+		return err
+	}
+	if s.EditedAt != nil {
+		indentedPrint(w, indent, false, false, "Edited", "%v", s.EditedAt.Local())
+	}
+	return nil
+}
+
 func (p *PlainPrinter) plainPrintUserToken(s *madon.UserToken, w io.Writer, indent string) error {
 	indentedPrint(w, indent, true, false, "User token", "%s", s.AccessToken)
 	indentedPrint(w, indent, false, true, "Type", "%s", s.TokenType)
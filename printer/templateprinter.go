@@ -19,6 +19,7 @@ import (
 	"github.com/mattn/go-isatty"
 
 	"github.com/McKael/madon/v3"
+	"github.com/McKael/madonctl/v3/mastodon"
 	"github.com/McKael/madonctl/v3/printer/colors"
 )
 
@@ -27,14 +28,17 @@ var disableColors bool
 
 // TemplatePrinter represents a Template printer
 type TemplatePrinter struct {
-	rawTemplate string
-	template    *template.Template
+	rawTemplate  string
+	template     *template.Template
+	showFiltered bool
 }
 
 // NewPrinterTemplate returns a Template ResourcePrinter
 // For TemplatePrinter, the options parameter contains the template string.
 // The "color_mode" option defines the color behaviour: it can be
 // "auto" (default), "on" (forced), "off" (disabled).
+// If the "show_filtered" option is "true", the statuses matching server-side
+// filters are displayed as usual.
 func NewPrinterTemplate(options Options) (*TemplatePrinter, error) {
 	tmpl := options["template"]
 	if tmpl == "" {
@@ -60,8 +64,9 @@ func NewPrinterTemplate(options Options) (*TemplatePrinter, error) {
 	}
 
 	return &TemplatePrinter{
-		rawTemplate: tmpl,
-		template:    t,
+		rawTemplate:  tmpl,
+		template:     t,
+		showFiltered: options["show_filtered"] == "true",
 	}, nil
 }
 
@@ -82,7 +87,10 @@ func (p *TemplatePrinter) PrintObj(obj interface{}, w io.Writer, tmpl string) er
 		[]madon.Instance, []madon.List, []madon.Mention,
 		[]madon.Notification, []madon.Relationship, []madon.Report,
 		[]madon.Results, []madon.Status, []madon.StreamEvent,
-		[]madon.Tag, []string:
+		[]madon.Tag, []mastodon.Account, []mastodon.Announcement,
+		[]mastodon.AnnouncementReactionEvent, []mastodon.Conversation,
+		[]mastodon.Filter, []mastodon.Relationship, []mastodon.Status,
+		[]string:
 		return p.templateForeach(ot, w)
 	}
 
@@ -98,6 +106,17 @@ func (p *TemplatePrinter) templatePrintSingleObj(obj interface{}, w io.Writer) e
 		return nil
 	}
 
+	// Apply server-side filters
+	switch s := obj.(type) {
+	case mastodon.Status:
+		obj = applyStatusFilters(&s, p.showFiltered)
+	case *mastodon.Status:
+		obj = applyStatusFilters(s, p.showFiltered)
+	}
+	if s, ok := obj.(*mastodon.Status); ok && s == nil {
+		return nil // Hidden by a server-side filter
+	}
+
 	// This code comes from Kubernetes.
 	data, err := json.Marshal(obj)
 	if err != nil {
@@ -16,15 +16,17 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/McKael/madon/v3"
+	"github.com/McKael/madonctl/v3/mastodon"
 )
 
 const themeDirName = "themes"
 
 // ThemePrinter represents a Theme printer
 type ThemePrinter struct {
-	name        string
-	templateDir string
-	colorMode   string
+	name         string
+	templateDir  string
+	colorMode    string
+	showFiltered string
 }
 
 // NewPrinterTheme returns a Theme ResourcePrinter
@@ -33,6 +35,7 @@ type ThemePrinter struct {
 // subdirectory).
 // The "color_mode" option defines the color behaviour: it can be
 // "auto" (default), "on" (forced), "off" (disabled).
+// The "show_filtered" option is passed to the underlying printers.
 func NewPrinterTheme(options Options) (*ThemePrinter, error) {
 	name, ok := options["name"]
 	if !ok || name == "" {
@@ -43,9 +46,10 @@ func NewPrinterTheme(options Options) (*ThemePrinter, error) {
 		return nil, fmt.Errorf("invalid theme name")
 	}
 	return &ThemePrinter{
-		name:        name,
-		templateDir: options["template_directory"],
-		colorMode:   options["color_mode"],
+		name:         name,
+		templateDir:  options["template_directory"],
+		colorMode:    options["color_mode"],
+		showFiltered: options["show_filtered"],
 	}, nil
 }
 
@@ -63,8 +67,13 @@ func (p *ThemePrinter) PrintObj(obj interface{}, w io.Writer, tmpl string) error
 	var objType string
 
 	switch obj.(type) {
-	case []madon.Account, madon.Account, *madon.Account:
+	case []madon.Account, madon.Account, *madon.Account,
+		[]mastodon.Account, mastodon.Account, *mastodon.Account:
 		objType = "account"
+	case []mastodon.Announcement, mastodon.Announcement, *mastodon.Announcement:
+		objType = "announcement"
+	case []mastodon.AnnouncementReactionEvent, mastodon.AnnouncementReactionEvent, *mastodon.AnnouncementReactionEvent:
+		objType = "announcement_reaction"
 	case []madon.Application, madon.Application, *madon.Application:
 		objType = "application"
 	case []madon.Attachment, madon.Attachment, *madon.Attachment:
@@ -75,8 +84,12 @@ func (p *ThemePrinter) PrintObj(obj interface{}, w io.Writer, tmpl string) error
 		objType = "client"
 	case []madon.Context, madon.Context, *madon.Context:
 		objType = "context"
+	case []mastodon.Conversation, mastodon.Conversation, *mastodon.Conversation:
+		objType = "conversation"
 	case []madon.Emoji, madon.Emoji, *madon.Emoji:
 		objType = "emoji"
+	case []mastodon.Filter, mastodon.Filter, *mastodon.Filter:
+		objType = "filter"
 	case []madon.Instance, madon.Instance, *madon.Instance:
 		objType = "instance"
 	case []madon.List, madon.List, *madon.List:
@@ -85,13 +98,15 @@ func (p *ThemePrinter) PrintObj(obj interface{}, w io.Writer, tmpl string) error
 		objType = "mention"
 	case []madon.Notification, madon.Notification, *madon.Notification:
 		objType = "notification"
-	case []madon.Relationship, madon.Relationship, *madon.Relationship:
+	case []madon.Relationship, madon.Relationship, *madon.Relationship,
+		[]mastodon.Relationship, mastodon.Relationship, *mastodon.Relationship:
 		objType = "relationship"
 	case []madon.Report, madon.Report, *madon.Report:
 		objType = "report"
 	case []madon.Results, madon.Results, *madon.Results:
 		objType = "results"
-	case []madon.Status, madon.Status, *madon.Status:
+	case []madon.Status, madon.Status, *madon.Status,
+		[]mastodon.Status, mastodon.Status, *mastodon.Status:
 		objType = "status"
 	case []madon.StreamEvent, madon.StreamEvent, *madon.StreamEvent:
 		objType = "stream_event"
@@ -112,8 +127,9 @@ func (p *ThemePrinter) PrintObj(obj interface{}, w io.Writer, tmpl string) error
 				return errors.Wrap(err, "cannot read template")
 			}
 			o := Options{
-				"template":   string(t),
-				"color_mode": p.colorMode,
+				"template":      string(t),
+				"color_mode":    p.colorMode,
+				"show_filtered": p.showFiltered,
 			}
 			np, err := NewPrinter("template", o)
 			if err != nil {
@@ -129,7 +145,7 @@ func (p *ThemePrinter) PrintObj(obj interface{}, w io.Writer, tmpl string) error
 
 	// No resource printer; let's fall back to plain printer
 	// XXX Maybe we should just fail?
-	plainP, err := NewPrinter("plain", nil)
+	plainP, err := NewPrinter("plain", Options{"show_filtered": p.showFiltered})
 	if err != nil {
 		return errors.Wrap(err, "cannot create plaintext printer")
 	}